@@ -0,0 +1,252 @@
+// process-template processes an OpenShift template with the same code path
+// as template.Tmpl, locally or against a cluster, and prints or applies the
+// resulting objects.
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"github.com/integr8ly/operator-sdk-openshift-utils/pkg/api/kubernetes"
+	"github.com/integr8ly/operator-sdk-openshift-utils/pkg/api/template"
+	"io"
+	"io/ioutil"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+	"os"
+	"strings"
+)
+
+type keyValues map[string]string
+
+func (kv keyValues) String() string {
+	pairs := make([]string, 0, len(kv))
+	for key, value := range kv {
+		pairs = append(pairs, key+"="+value)
+	}
+
+	return strings.Join(pairs, ",")
+}
+
+func (kv keyValues) Set(pair string) error {
+	parts := strings.SplitN(pair, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return fmt.Errorf("expected KEY=VALUE, got %q", pair)
+	}
+
+	kv[parts[0]] = parts[1]
+	return nil
+}
+
+type filters []template.FilterFn
+
+func (f *filters) String() string {
+	return ""
+}
+
+func (f *filters) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("expected FIELD=VALUE, got %q", value)
+	}
+
+	switch parts[0] {
+	case "kind":
+		*f = append(*f, template.ByKind(strings.Split(parts[1], ",")...))
+	case "name":
+		*f = append(*f, template.ByName(strings.Split(parts[1], ",")...))
+	case "label":
+		filter, err := template.ByLabelSelector(parts[1])
+		if err != nil {
+			return err
+		}
+		*f = append(*f, filter)
+	case "annotation":
+		annotation := strings.SplitN(parts[1], "=", 2)
+		*f = append(*f, template.ByAnnotation(annotation[0], annotation[1:]...))
+	default:
+		return fmt.Errorf("unknown filter field %q, expected kind, name, label or annotation", parts[0])
+	}
+
+	return nil
+}
+
+type options struct {
+	file       string
+	params     keyValues
+	paramFile  string
+	output     string
+	local      bool
+	kubeconfig string
+	namespace  string
+	filters    filters
+	apply      bool
+	strict     bool
+}
+
+func main() {
+	err := run(os.Args[1:], os.Stdout, os.Stderr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	opts, err := parseFlags(args, stderr)
+	if err != nil {
+		return err
+	}
+
+	data, err := ioutil.ReadFile(opts.file)
+	if err != nil {
+		return err
+	}
+
+	if opts.strict {
+		_, err = kubernetes.DecodeAnyWithOptions(bytes.NewReader(data), kubernetes.DecodeOpt{AllowUnstructured: true, Strict: true})
+		if err != nil {
+			return err
+		}
+	}
+
+	fileParams := make(template.Params)
+	if opts.paramFile != "" {
+		fileParams, err = template.ParamsFromEnvFile(opts.paramFile)
+		if err != nil {
+			return err
+		}
+	}
+	params := template.MergeParams(fileParams, template.ParamsFromMap(opts.params, "command line"))
+
+	var cfg *rest.Config
+	ns := opts.namespace
+	if !opts.local || opts.apply {
+		rules := clientcmd.NewDefaultClientConfigLoadingRules()
+		rules.ExplicitPath = opts.kubeconfig
+		clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{})
+
+		cfg, err = clientConfig.ClientConfig()
+		if err != nil {
+			return err
+		}
+
+		if ns == "" {
+			ns, _, err = clientConfig.Namespace()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	processCfg := cfg
+	if opts.local {
+		processCfg = nil
+	}
+
+	tmpl, err := template.New(processCfg, data)
+	if err != nil {
+		return err
+	}
+	// custom resources are printed or applied as they are
+	tmpl.AllowUnstructured = true
+
+	err = tmpl.ValidateParams(params)
+	if err != nil {
+		return err
+	}
+
+	if opts.local {
+		err = tmpl.ProcessLocal(params.Values())
+	} else {
+		err = tmpl.Process(params.Values(), ns)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(opts.filters) > 0 {
+		tmpl.Objects, err = tmpl.GetObjectsWithError(template.And(opts.filters...))
+		if err != nil {
+			return err
+		}
+	}
+
+	if !opts.apply {
+		format := template.RenderYAML
+		if opts.output == "json" {
+			format = template.RenderJSON
+		}
+
+		return tmpl.Render(stdout, format)
+	}
+
+	tmpl.Order = template.InstallOrder
+	return apply(cfg, ns, tmpl, stdout)
+}
+
+func parseFlags(args []string, stderr io.Writer) (*options, error) {
+	opts := &options{params: make(keyValues)}
+
+	flags := flag.NewFlagSet("process-template", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(&opts.file, "f", "", "template file, in json or yaml")
+	flags.Var(opts.params, "p", "template parameter as KEY=VALUE, can be repeated")
+	flags.StringVar(&opts.paramFile, "param-file", "", "env file with a KEY=VALUE parameter per line")
+	flags.StringVar(&opts.output, "o", "yaml", "output format, yaml or json")
+	flags.BoolVar(&opts.local, "local", false, "process the template locally instead of on the cluster")
+	flags.StringVar(&opts.kubeconfig, "kubeconfig", "", "path to the kubeconfig file")
+	flags.StringVar(&opts.namespace, "n", "", "namespace, defaults to the one of the kubeconfig context")
+	flags.Var(&opts.filters, "filter", "only keep the objects matching kind=, name=, label= or annotation=, can be repeated")
+	flags.BoolVar(&opts.apply, "apply", false, "create or update the objects on the cluster instead of printing them")
+	flags.BoolVar(&opts.strict, "strict", false, "fail on unknown fields and duplicate keys in the template and its objects")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.file == "" {
+		return nil, fmt.Errorf("a template file is required")
+	}
+
+	if opts.output != "yaml" && opts.output != "json" {
+		return nil, fmt.Errorf("unknown output format %q, expected yaml or json", opts.output)
+	}
+
+	return opts, nil
+}
+
+func apply(cfg *rest.Config, ns string, tmpl *template.Tmpl, stdout io.Writer) error {
+	objects, err := tmpl.GetObjectsWithError(template.NoFilterFn)
+	if err != nil {
+		return err
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	resources, err := restmapper.GetAPIGroupResources(discoveryClient)
+	if err != nil {
+		return err
+	}
+
+	client := template.NewDynamicClient(dynamicClient, restmapper.NewDiscoveryRESTMapper(resources))
+	report, err := template.NewApplier(client, ns).Apply(context.TODO(), objects)
+	for _, result := range report {
+		fmt.Fprintf(stdout, "%s/%s %s\n", strings.ToLower(result.GroupVersionKind.Kind), result.Name, strings.ToLower(string(result.Action)))
+	}
+
+	return err
+}
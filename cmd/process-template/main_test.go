@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	cases := []struct {
+		Name        string
+		Args        []string
+		Validate    func(out string)
+		ExpectError bool
+	}{
+		{
+			Name: "Should process locally with parameters from the command line",
+			Args: []string{"-f", "_testdata/template.yaml", "--local", "-p", "APP_NAME=cli-app"},
+			Validate: func(out string) {
+				if !strings.Contains(out, "kind: List") || !strings.Contains(out, "name: cli-app") || !strings.Contains(out, "host: cli-app.example.com") {
+					t.Fatalf("Unexpected output:\n%s", out)
+				}
+			},
+			ExpectError: false,
+		},
+		{
+			Name: "Should let command line parameters override the parameter file",
+			Args: []string{"-f", "_testdata/template.yaml", "--local", "--param-file", "_testdata/params.env", "-p", "APP_NAME=cli-app", "-o", "json"},
+			Validate: func(out string) {
+				if !strings.Contains(out, `"name": "cli-app"`) || !strings.Contains(out, `"host": "from-file.example.com"`) {
+					t.Fatalf("Unexpected output:\n%s", out)
+				}
+			},
+			ExpectError: false,
+		},
+		{
+			Name: "Should filter the objects",
+			Args: []string{"-f", "_testdata/template.yaml", "--local", "--param-file", "_testdata/params.env", "--filter", "kind=Route"},
+			Validate: func(out string) {
+				if strings.Contains(out, "kind: Service\n  metadata") || !strings.Contains(out, "kind: Route") {
+					t.Fatalf("Only routes should be kept:\n%s", out)
+				}
+			},
+			ExpectError: false,
+		},
+		{
+			Name:        "Should fail when a required parameter is missing",
+			Args:        []string{"-f", "_testdata/template.yaml", "--local"},
+			Validate:    func(out string) {},
+			ExpectError: true,
+		},
+		{
+			Name:        "Should fail on undeclared parameters",
+			Args:        []string{"-f", "_testdata/template.yaml", "--local", "-p", "APP_NAME=cli-app", "-p", "UNKNOWN=value"},
+			Validate:    func(out string) {},
+			ExpectError: true,
+		},
+		{
+			Name:        "Should fail on unknown filters",
+			Args:        []string{"-f", "_testdata/template.yaml", "--local", "--filter", "owner=me"},
+			Validate:    func(out string) {},
+			ExpectError: true,
+		},
+		{
+			Name: "Should accept a valid template in strict mode",
+			Args: []string{"-f", "_testdata/template.yaml", "--local", "-p", "APP_NAME=cli-app", "--strict"},
+			Validate: func(out string) {
+				if !strings.Contains(out, "kind: List") {
+					t.Fatalf("Unexpected output:\n%s", out)
+				}
+			},
+			ExpectError: false,
+		},
+		{
+			Name:        "Should fail on unknown fields in strict mode",
+			Args:        []string{"-f", "_testdata/template-typo.yaml", "--local", "--strict"},
+			Validate:    func(out string) {},
+			ExpectError: true,
+		},
+		{
+			Name: "Should ignore unknown fields by default",
+			Args: []string{"-f", "_testdata/template-typo.yaml", "--local"},
+			Validate: func(out string) {
+				if strings.Contains(out, "protocl") {
+					t.Fatalf("Unknown fields should be dropped:\n%s", out)
+				}
+			},
+			ExpectError: false,
+		},
+		{
+			Name:        "Should fail on unknown output formats",
+			Args:        []string{"-f", "_testdata/template.yaml", "--local", "-o", "xml"},
+			Validate:    func(out string) {},
+			ExpectError: true,
+		},
+	}
+
+	for _, tc := range cases {
+		stdout := &bytes.Buffer{}
+		err := run(tc.Args, stdout, &bytes.Buffer{})
+
+		if tc.ExpectError && err == nil {
+			t.Fatalf("\"%s\" expected an error but got none", tc.Name)
+		}
+
+		if !tc.ExpectError && err != nil {
+			t.Fatalf("\"%s\" did not expect error but got %s", tc.Name, err)
+		}
+
+		tc.Validate(stdout.String())
+	}
+}
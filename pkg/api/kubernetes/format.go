@@ -0,0 +1,65 @@
+package kubernetes
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/ghodss/yaml"
+	"io"
+	"io/ioutil"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+)
+
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// DetectFormat sniffs data, which is json when it is a valid json document
+// and yaml otherwise, including flow style yaml like {kind: Service} and
+// documents starting with comments or a --- separator.
+func DetectFormat(data []byte) Format {
+	if json.Valid(bytes.TrimPrefix(data, utf8BOM)) {
+		return FormatJSON
+	}
+
+	return FormatYAML
+}
+
+// AsJSON returns data as json, converting it when it is yaml.
+func AsJSON(data []byte) ([]byte, error) {
+	data = bytes.TrimPrefix(data, utf8BOM)
+	if DetectFormat(data) == FormatJSON {
+		return data, nil
+	}
+
+	// util/yaml.ToJSON would return flow style yaml as is
+	return yaml.YAMLToJSON(data)
+}
+
+// DecodeAny reads a single json or yaml object, whatever the source of the
+// reader: a file, a template or a http body.
+func DecodeAny(reader io.Reader) (runtime.Object, error) {
+	return DecodeAnyWithOptions(reader, DecodeDefaultOpts)
+}
+
+func DecodeAnyWithOptions(reader io.Reader, opts DecodeOpt) (runtime.Object, error) {
+	return defaultLoader.DecodeAny(reader, opts)
+}
+
+func (l *Loader) DecodeAny(reader io.Reader, opts DecodeOpt) (runtime.Object, error) {
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonData, err := AsJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.decode(data, jsonData, opts)
+}
@@ -0,0 +1,122 @@
+package kubernetes
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Data     string
+		Expected Format
+	}{
+		{
+			Name:     "Should detect json objects",
+			Data:     "\n  {\"kind\": \"Service\"}",
+			Expected: FormatJSON,
+		},
+		{
+			Name:     "Should detect json with a byte order mark",
+			Data:     "\xef\xbb\xbf{\"kind\": \"Service\"}",
+			Expected: FormatJSON,
+		},
+		{
+			Name:     "Should detect yaml",
+			Data:     "kind: Service\n",
+			Expected: FormatYAML,
+		},
+		{
+			Name:     "Should detect flow style yaml",
+			Data:     "{kind: Service, metadata: {name: web}}\n",
+			Expected: FormatYAML,
+		},
+		{
+			Name:     "Should detect yaml with comments and a separator",
+			Data:     "# a service\n---\nkind: Service\n",
+			Expected: FormatYAML,
+		},
+	}
+
+	for _, tc := range cases {
+		if format := DetectFormat([]byte(tc.Data)); format != tc.Expected {
+			t.Fatalf("\"%s\" expected %s but got %s", tc.Name, tc.Expected, format)
+		}
+	}
+}
+
+func TestDecodeAny(t *testing.T) {
+	cases := []struct {
+		Name        string
+		Reader      func() *strings.Reader
+		Kind        string
+		ExpectError bool
+	}{
+		{
+			Name: "Should decode json",
+			Reader: func() *strings.Reader {
+				return strings.NewReader(`{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "web"}}`)
+			},
+			Kind:        "Service",
+			ExpectError: false,
+		},
+		{
+			Name: "Should decode yaml with comments and a separator",
+			Reader: func() *strings.Reader {
+				return strings.NewReader("# web service\n---\napiVersion: v1\nkind: Service\nmetadata:\n  name: web\n")
+			},
+			Kind:        "Service",
+			ExpectError: false,
+		},
+		{
+			Name: "Should decode flow style yaml",
+			Reader: func() *strings.Reader {
+				return strings.NewReader("{apiVersion: v1, kind: Service, metadata: {name: web}}\n")
+			},
+			Kind:        "Service",
+			ExpectError: false,
+		},
+		{
+			Name: "Should fail on invalid yaml",
+			Reader: func() *strings.Reader {
+				return strings.NewReader("kind: [Service\n")
+			},
+			ExpectError: true,
+		},
+	}
+
+	for _, tc := range cases {
+		obj, err := DecodeAny(tc.Reader())
+
+		if tc.ExpectError {
+			if err == nil {
+				t.Fatalf("\"%s\" expected an error but got none", tc.Name)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("\"%s\" did not expect error but got %s", tc.Name, err)
+		}
+
+		if kind := obj.GetObjectKind().GroupVersionKind().Kind; kind != tc.Kind {
+			t.Fatalf("\"%s\" expected kind %s but got %s", tc.Name, tc.Kind, kind)
+		}
+	}
+}
+
+func TestDecodeAny_File(t *testing.T) {
+	for _, path := range []string{"_testdata/test-template.json", "_testdata/test-template.yaml"} {
+		file, err := os.Open(path)
+		if err != nil {
+			t.Fatalf("Failed to open mock file: %v", err)
+		}
+
+		_, err = DecodeAny(file)
+		file.Close()
+		if err != nil {
+			t.Fatalf("Failed to decode %s: %v", path, err)
+		}
+	}
+}
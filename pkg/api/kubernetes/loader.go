@@ -0,0 +1,21 @@
+package kubernetes
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Loader decodes objects into the types registered in Scheme and stamps
+// the kinds of the objects it writes. The package functions use a loader
+// over the types of the schemes package, pass the scheme of the manager to
+// decode the operator's own types and the core kubernetes ones too.
+type Loader struct {
+	Scheme *runtime.Scheme
+}
+
+var defaultLoader = NewLoader(scheme)
+
+func NewLoader(scheme *runtime.Scheme) *Loader {
+	return &Loader{
+		Scheme: scheme,
+	}
+}
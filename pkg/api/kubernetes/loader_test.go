@@ -0,0 +1,135 @@
+package kubernetes
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"strings"
+	"testing"
+)
+
+var webAppGroupVersion = schema.GroupVersion{Group: "integreatly.org", Version: "v1alpha1"}
+
+type webApp struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              webAppSpec `json:"spec,omitempty"`
+}
+
+type webAppSpec struct {
+	Size int32 `json:"size,omitempty"`
+}
+
+func (w *webApp) DeepCopyObject() runtime.Object {
+	out := *w
+	w.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+
+	return &out
+}
+
+func newTestLoader(t *testing.T) *Loader {
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypeWithName(webAppGroupVersion.WithKind("WebApp"), &webApp{})
+
+	err := corev1.AddToScheme(scheme)
+	if err != nil {
+		t.Fatalf("Failed to create scheme: %v", err)
+	}
+
+	return NewLoader(scheme)
+}
+
+func TestLoader_LoadKubernetesResource(t *testing.T) {
+	cases := []struct {
+		Name        string
+		Data        string
+		Opts        DecodeOpt
+		Validate    func(obj runtime.Object)
+		ExpectError bool
+	}{
+		{
+			Name: "Should decode the types of the scheme",
+			Data: `{"apiVersion": "integreatly.org/v1alpha1", "kind": "WebApp", "metadata": {"name": "web"}, "spec": {"size": 3}}`,
+			Opts: DecodeDefaultOpts,
+			Validate: func(obj runtime.Object) {
+				app, ok := obj.(*webApp)
+				if !ok || app.Name != "web" || app.Spec.Size != 3 {
+					t.Fatalf("Unexpected object: %#v", obj)
+				}
+			},
+			ExpectError: false,
+		},
+		{
+			Name:        "Should only know the types of the scheme",
+			Data:        `{"apiVersion": "route.openshift.io/v1", "kind": "Route", "metadata": {"name": "web"}}`,
+			Opts:        DecodeDefaultOpts,
+			Validate:    func(obj runtime.Object) {},
+			ExpectError: true,
+		},
+		{
+			Name: "Should fall back to unstructured for the kinds missing from the scheme",
+			Data: `{"apiVersion": "route.openshift.io/v1", "kind": "Route", "metadata": {"name": "web"}}`,
+			Opts: DecodeUnstructuredOpts,
+			Validate: func(obj runtime.Object) {
+				if !IsUnstructured(obj) {
+					t.Fatalf("Unexpected object: %#v", obj)
+				}
+			},
+			ExpectError: false,
+		},
+		{
+			Name:        "Should check the fields of the types of the scheme",
+			Data:        `{"apiVersion": "integreatly.org/v1alpha1", "kind": "WebApp", "metadata": {"name": "web"}, "spec": {"sise": 3}}`,
+			Opts:        DecodeStrictOpts,
+			Validate:    func(obj runtime.Object) {},
+			ExpectError: true,
+		},
+	}
+
+	loader := newTestLoader(t)
+	for _, tc := range cases {
+		obj, err := loader.LoadKubernetesResource([]byte(tc.Data), tc.Opts)
+
+		if tc.ExpectError && err == nil {
+			t.Fatalf("\"%s\" expected an error but got none", tc.Name)
+		}
+
+		if !tc.ExpectError && err != nil {
+			t.Fatalf("\"%s\" did not expect error but got %s", tc.Name, err)
+		}
+
+		tc.Validate(obj)
+	}
+}
+
+func TestLoader_DefaultScheme(t *testing.T) {
+	data := `{"apiVersion": "integreatly.org/v1alpha1", "kind": "WebApp", "metadata": {"name": "web"}}`
+
+	_, err := LoadKubernetesResource([]byte(data))
+	if err == nil {
+		t.Fatal("The types of a loader should not leak into the package scheme")
+	}
+
+	_, err = LoadKubernetesResource([]byte(`{"apiVersion": "route.openshift.io/v1", "kind": "Route", "metadata": {"name": "web"}}`))
+	if err != nil {
+		t.Fatalf("The package functions should know the openshift types: %v", err)
+	}
+}
+
+func TestLoader_ToYAML(t *testing.T) {
+	b, err := newTestLoader(t).ToYAML(&webApp{ObjectMeta: metav1.ObjectMeta{Name: "web"}, Spec: webAppSpec{Size: 3}}, SerializeOpt{DropReadOnlyMetadata: true})
+	if err != nil {
+		t.Fatalf("Test failed: %v", err)
+	}
+
+	expected := "apiVersion: integreatly.org/v1alpha1\nkind: WebApp\nmetadata:\n  name: web\nspec:\n  size: 3\n"
+	if string(b) != expected {
+		t.Fatalf("Unexpected yaml: %s", b)
+	}
+
+	_, err = ToYAML(&webApp{})
+	if err == nil || !strings.Contains(err.Error(), "failed to find the kind") {
+		t.Fatalf("Expected the package scheme not to know the type: %v", err)
+	}
+}
@@ -0,0 +1,207 @@
+package kubernetes
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"os"
+	"path/filepath"
+)
+
+// ResourceError locates the document that failed to load. Path is empty
+// when loading from a reader.
+type ResourceError struct {
+	Path     string
+	Document int
+	Err      error
+}
+
+func (e *ResourceError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("document %d: %v", e.Document, e.Err)
+	}
+
+	return fmt.Sprintf("%s: document %d: %v", e.Path, e.Document, e.Err)
+}
+
+// LoadKubernetesResources reads every object of a stream of yaml documents
+// or json objects. The items of a v1 List are returned as separate objects
+// and empty documents are skipped. Errors are *ResourceError values.
+func LoadKubernetesResources(reader io.Reader) ([]runtime.Object, error) {
+	return LoadKubernetesResourcesWithOptions(reader, DecodeDefaultOpts)
+}
+
+func LoadKubernetesResourcesWithOptions(reader io.Reader, opts DecodeOpt) ([]runtime.Object, error) {
+	return defaultLoader.LoadKubernetesResources(reader, opts)
+}
+
+func (l *Loader) LoadKubernetesResources(reader io.Reader, opts DecodeOpt) ([]runtime.Object, error) {
+	objects := make([]runtime.Object, 0)
+	next := documents(reader)
+
+	for document := 0; ; document++ {
+		data, err := next()
+		if err == io.EOF {
+			return objects, nil
+		}
+		if err != nil {
+			return nil, &ResourceError{Document: document, Err: err}
+		}
+
+		raw, err := AsJSON(data)
+		if err != nil {
+			return nil, &ResourceError{Document: document, Err: err}
+		}
+
+		raw = bytes.TrimSpace(raw)
+		if len(raw) == 0 || bytes.Equal(raw, []byte("null")) || bytes.Equal(raw, []byte("{}")) {
+			continue
+		}
+
+		// keeps integers as int64, decoding into a map would make them float64
+		u := &unstructured.Unstructured{}
+		err = u.UnmarshalJSON(raw)
+		if err != nil {
+			return nil, &ResourceError{Document: document, Err: err}
+		}
+
+		items, err := listItems(u)
+		if err != nil {
+			return nil, &ResourceError{Document: document, Err: err}
+		}
+
+		fieldErrs := make(FieldErrors, 0)
+		if opts.Strict {
+			fieldErrs = duplicateFields(data)
+		}
+
+		for i, item := range items {
+			obj, err := l.RuntimeObjectFromUnstructured(item, opts)
+			if unknown, ok := err.(FieldErrors); ok {
+				if item != u {
+					fieldErrs.appendNested(fmt.Sprintf("$.items[%d]", i), unknown)
+				} else {
+					fieldErrs = append(fieldErrs, unknown...)
+				}
+				continue
+			}
+			if err != nil {
+				if item != u {
+					err = fmt.Errorf("item %d: %v", i, err)
+				}
+				return nil, &ResourceError{Document: document, Err: err}
+			}
+
+			objects = append(objects, obj)
+		}
+
+		if len(fieldErrs) > 0 {
+			return nil, &ResourceError{Document: document, Err: fieldErrs}
+		}
+	}
+}
+
+// documents returns a function reading the next json object or yaml
+// document of the stream as it was written, io.EOF at the end.
+func documents(reader io.Reader) func() ([]byte, error) {
+	reader, _, isJSON := yaml.GuessJSONStream(reader, 4096)
+	if !isJSON {
+		return yaml.NewYAMLReader(bufio.NewReader(reader)).Read
+	}
+
+	decoder := json.NewDecoder(reader)
+	return func() ([]byte, error) {
+		var raw json.RawMessage
+		err := decoder.Decode(&raw)
+
+		return raw, err
+	}
+}
+
+// LoadKubernetesResourcesFromDir reads the objects of every yaml and json
+// file of a directory, in file name order, descending into subdirectories
+// when recursive is set. Errors are *ResourceError values.
+func LoadKubernetesResourcesFromDir(path string, recursive bool) ([]runtime.Object, error) {
+	return defaultLoader.LoadKubernetesResourcesFromDir(path, recursive, DecodeDefaultOpts)
+}
+
+func (l *Loader) LoadKubernetesResourcesFromDir(path string, recursive bool, opts DecodeOpt) ([]runtime.Object, error) {
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]runtime.Object, 0)
+	for _, file := range files {
+		filePath := filepath.Join(path, file.Name())
+
+		if file.IsDir() {
+			if !recursive {
+				continue
+			}
+
+			dirObjects, err := l.LoadKubernetesResourcesFromDir(filePath, recursive, opts)
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, dirObjects...)
+			continue
+		}
+
+		if !isManifest(file.Name()) {
+			continue
+		}
+
+		fileObjects, err := l.loadKubernetesResourcesFromFile(filePath, opts)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, fileObjects...)
+	}
+
+	return objects, nil
+}
+
+func (l *Loader) loadKubernetesResourcesFromFile(path string, opts DecodeOpt) ([]runtime.Object, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	objects, err := l.LoadKubernetesResources(file, opts)
+	if err, ok := err.(*ResourceError); ok {
+		err.Path = path
+		return nil, err
+	}
+
+	return objects, err
+}
+
+func listItems(u *unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+	if !u.IsList() {
+		return []*unstructured.Unstructured{u}, nil
+	}
+
+	list, err := u.ToList()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*unstructured.Unstructured, len(list.Items))
+	for i := range list.Items {
+		items[i] = &list.Items[i]
+	}
+
+	return items, nil
+}
+
+func isManifest(filename string) bool {
+	return isYaml(filename) || filepath.Ext(filename) == ".json"
+}
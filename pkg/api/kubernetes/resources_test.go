@@ -0,0 +1,118 @@
+package kubernetes
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"os"
+	"strings"
+	"testing"
+)
+
+func kinds(objects []runtime.Object) []string {
+	kinds := make([]string, 0, len(objects))
+	for _, obj := range objects {
+		kinds = append(kinds, obj.GetObjectKind().GroupVersionKind().Kind)
+	}
+
+	return kinds
+}
+
+func TestLoadKubernetesResources(t *testing.T) {
+	cases := []struct {
+		Name        string
+		FilePath    string
+		Kinds       []string
+		ExpectError string
+	}{
+		{
+			Name:     "Should load every yaml document",
+			FilePath: "_testdata/multi-doc.yaml",
+			Kinds:    []string{"Service", "Route"},
+		},
+		{
+			Name:     "Should load the items of a list",
+			FilePath: "_testdata/list.json",
+			Kinds:    []string{"ConfigMap", "Secret"},
+		},
+		{
+			Name:     "Should load a single json object",
+			FilePath: "_testdata/test-template.json",
+			Kinds:    []string{"Template"},
+		},
+		{
+			Name:        "Should report the failing document",
+			FilePath:    "_testdata/invalid-multi-doc.yaml",
+			ExpectError: "document 1:",
+		},
+	}
+
+	for _, tc := range cases {
+		file, err := os.Open(tc.FilePath)
+		if err != nil {
+			t.Fatalf("Failed to open mock file: %v", err)
+		}
+
+		objects, err := LoadKubernetesResources(file)
+		file.Close()
+
+		if tc.ExpectError != "" {
+			if err == nil || !strings.HasPrefix(err.Error(), tc.ExpectError) {
+				t.Fatalf("\"%s\" expected error %s but got %v", tc.Name, tc.ExpectError, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("\"%s\" did not expect error but got %s", tc.Name, err)
+		}
+
+		if strings.Join(kinds(objects), ",") != strings.Join(tc.Kinds, ",") {
+			t.Fatalf("\"%s\" expected kinds %v but got %v", tc.Name, tc.Kinds, kinds(objects))
+		}
+	}
+}
+
+func TestLoadKubernetesResourcesFromDir(t *testing.T) {
+	cases := []struct {
+		Name      string
+		Path      string
+		Recursive bool
+		Kinds     []string
+	}{
+		{
+			Name:      "Should load the manifests of a directory",
+			Path:      "_testdata/manifests",
+			Recursive: false,
+			Kinds:     []string{"ConfigMap", "Service"},
+		},
+		{
+			Name:      "Should load the manifests of subdirectories",
+			Path:      "_testdata/manifests",
+			Recursive: true,
+			Kinds:     []string{"ConfigMap", "Route", "Service"},
+		},
+	}
+
+	for _, tc := range cases {
+		objects, err := LoadKubernetesResourcesFromDir(tc.Path, tc.Recursive)
+		if err != nil {
+			t.Fatalf("\"%s\" did not expect error but got %s", tc.Name, err)
+		}
+
+		if strings.Join(kinds(objects), ",") != strings.Join(tc.Kinds, ",") {
+			t.Fatalf("\"%s\" expected kinds %v but got %v", tc.Name, tc.Kinds, kinds(objects))
+		}
+	}
+}
+
+func TestLoadKubernetesResourcesFromDir_Error(t *testing.T) {
+	_, err := LoadKubernetesResourcesFromDir("_testdata", false)
+
+	resourceErr, ok := err.(*ResourceError)
+	if !ok {
+		t.Fatalf("Expected a resource error but got %v", err)
+	}
+
+	if resourceErr.Path != "_testdata/invalid-multi-doc.yaml" || resourceErr.Document != 1 {
+		t.Fatalf("Unexpected error location: %v", resourceErr)
+	}
+}
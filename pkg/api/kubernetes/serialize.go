@@ -0,0 +1,109 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ghodss/yaml"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// SerializeOpt controls how objects are written. Pretty indents json, yaml
+// is always written in block style. DropReadOnlyMetadata removes the
+// metadata fields set by the api server, so the output can be created
+// again on another cluster.
+type SerializeOpt struct {
+	Pretty               bool
+	DropReadOnlyMetadata bool
+}
+
+var (
+	SerializeDefaultOpts = SerializeOpt{
+		Pretty:               false,
+		DropReadOnlyMetadata: false,
+	}
+
+	readOnlyMetadataFields = []string{
+		"uid",
+		"resourceVersion",
+		"generation",
+		"creationTimestamp",
+		"deletionTimestamp",
+		"deletionGracePeriodSeconds",
+		"selfLink",
+		"managedFields",
+	}
+)
+
+func ToUnstructured(obj runtime.Object) (*unstructured.Unstructured, error) {
+	return ToUnstructuredWithOptions(obj, SerializeDefaultOpts)
+}
+
+func ToUnstructuredWithOptions(obj runtime.Object, opts SerializeOpt) (*unstructured.Unstructured, error) {
+	return defaultLoader.ToUnstructured(obj, opts)
+}
+
+// ToUnstructured converts obj, stamping the apiVersion and kind registered
+// in the scheme when obj has none. obj is left untouched.
+func (l *Loader) ToUnstructured(obj runtime.Object, opts SerializeOpt) (*unstructured.Unstructured, error) {
+	obj = obj.DeepCopyObject()
+
+	if obj.GetObjectKind().GroupVersionKind().Empty() {
+		gvks, _, err := l.Scheme.ObjectKinds(obj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the kind of %T: %v", obj, err)
+		}
+		obj.GetObjectKind().SetGroupVersionKind(gvks[0])
+	}
+
+	u, err := UnstructuredFromRuntimeObject(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.DropReadOnlyMetadata {
+		for _, field := range readOnlyMetadataFields {
+			unstructured.RemoveNestedField(u.Object, "metadata", field)
+		}
+	}
+
+	return u, nil
+}
+
+func ToJSON(obj runtime.Object) ([]byte, error) {
+	return ToJSONWithOptions(obj, SerializeDefaultOpts)
+}
+
+func ToJSONWithOptions(obj runtime.Object, opts SerializeOpt) ([]byte, error) {
+	return defaultLoader.ToJSON(obj, opts)
+}
+
+func (l *Loader) ToJSON(obj runtime.Object, opts SerializeOpt) ([]byte, error) {
+	u, err := l.ToUnstructured(obj, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Pretty {
+		return json.MarshalIndent(u.Object, "", "  ")
+	}
+
+	return json.Marshal(u.Object)
+}
+
+func ToYAML(obj runtime.Object) ([]byte, error) {
+	return ToYAMLWithOptions(obj, SerializeDefaultOpts)
+}
+
+func ToYAMLWithOptions(obj runtime.Object, opts SerializeOpt) ([]byte, error) {
+	return defaultLoader.ToYAML(obj, opts)
+}
+
+func (l *Loader) ToYAML(obj runtime.Object, opts SerializeOpt) ([]byte, error) {
+	u, err := l.ToUnstructured(obj, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(u.Object)
+}
@@ -0,0 +1,142 @@
+package kubernetes
+
+import (
+	appsv1 "github.com/openshift/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"strings"
+	"testing"
+)
+
+func TestToUnstructuredWithOptions(t *testing.T) {
+	cases := []struct {
+		Name        string
+		Object      runtime.Object
+		Opts        SerializeOpt
+		Validate    func(u *unstructured.Unstructured)
+		ExpectError bool
+	}{
+		{
+			Name:   "Should stamp the kind of core objects",
+			Object: &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web"}},
+			Opts:   SerializeDefaultOpts,
+			Validate: func(u *unstructured.Unstructured) {
+				if u.GetAPIVersion() != "v1" || u.GetKind() != "Service" || u.GetName() != "web" {
+					t.Fatalf("Unexpected object: %v", u)
+				}
+			},
+			ExpectError: false,
+		},
+		{
+			Name:   "Should stamp the kind of openshift objects",
+			Object: &appsv1.DeploymentConfig{},
+			Opts:   SerializeDefaultOpts,
+			Validate: func(u *unstructured.Unstructured) {
+				if u.GetAPIVersion() != "apps.openshift.io/v1" || u.GetKind() != "DeploymentConfig" {
+					t.Fatalf("Unexpected object: %v", u)
+				}
+			},
+			ExpectError: false,
+		},
+		{
+			Name: "Should keep the kind set on the object",
+			Object: &appsv1.DeploymentConfig{
+				TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "DeploymentConfig"},
+			},
+			Opts: SerializeDefaultOpts,
+			Validate: func(u *unstructured.Unstructured) {
+				if u.GetAPIVersion() != "v1" {
+					t.Fatalf("Unexpected object: %v", u)
+				}
+			},
+			ExpectError: false,
+		},
+		{
+			Name: "Should drop read only metadata",
+			Object: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "config",
+					UID:               "1234",
+					ResourceVersion:   "42",
+					CreationTimestamp: metav1.Now(),
+				},
+			},
+			Opts: SerializeOpt{DropReadOnlyMetadata: true},
+			Validate: func(u *unstructured.Unstructured) {
+				metadata := u.Object["metadata"].(map[string]interface{})
+				if len(metadata) != 1 || metadata["name"] != "config" {
+					t.Fatalf("Unexpected metadata: %v", metadata)
+				}
+			},
+			ExpectError: false,
+		},
+		{
+			Name:        "Should fail on unstructured objects without a kind",
+			Object:      &unstructured.Unstructured{Object: map[string]interface{}{}},
+			Opts:        SerializeDefaultOpts,
+			Validate:    func(u *unstructured.Unstructured) {},
+			ExpectError: true,
+		},
+	}
+
+	for _, tc := range cases {
+		u, err := ToUnstructuredWithOptions(tc.Object, tc.Opts)
+
+		if tc.ExpectError && err == nil {
+			t.Fatalf("\"%s\" expected an error but got none", tc.Name)
+		}
+
+		if !tc.ExpectError && err != nil {
+			t.Fatalf("\"%s\" did not expect error but got %s", tc.Name, err)
+		}
+
+		tc.Validate(u)
+	}
+}
+
+func TestToJSONWithOptions(t *testing.T) {
+	obj := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "config"}}
+
+	compact, err := ToJSON(obj)
+	if err != nil {
+		t.Fatalf("Test failed: %v", err)
+	}
+
+	expected := `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"creationTimestamp":null,"name":"config"}}`
+	if string(compact) != expected {
+		t.Fatalf("Unexpected json: %s", compact)
+	}
+
+	if obj.Kind != "" {
+		t.Fatalf("The object should not be modified: %v", obj)
+	}
+
+	pretty, err := ToJSONWithOptions(obj, SerializeOpt{Pretty: true, DropReadOnlyMetadata: true})
+	if err != nil {
+		t.Fatalf("Test failed: %v", err)
+	}
+
+	expected = "{\n  \"apiVersion\": \"v1\",\n  \"kind\": \"ConfigMap\",\n  \"metadata\": {\n    \"name\": \"config\"\n  }\n}"
+	if string(pretty) != expected {
+		t.Fatalf("Unexpected json: %s", pretty)
+	}
+}
+
+func TestToYAML(t *testing.T) {
+	b, err := ToYAMLWithOptions(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "config"}}, SerializeOpt{DropReadOnlyMetadata: true})
+	if err != nil {
+		t.Fatalf("Test failed: %v", err)
+	}
+
+	expected := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n"
+	if string(b) != expected {
+		t.Fatalf("Unexpected yaml: %s", b)
+	}
+
+	obj, err := DecodeAny(strings.NewReader(string(b)))
+	if err != nil || obj.(*corev1.ConfigMap).Name != "config" {
+		t.Fatalf("Failed to decode the yaml back: %v", err)
+	}
+}
@@ -0,0 +1,279 @@
+package kubernetes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type FieldErrorType string
+
+const (
+	FieldErrorUnknown   FieldErrorType = "Unknown"
+	FieldErrorDuplicate FieldErrorType = "Duplicate"
+)
+
+var (
+	identifierExp    = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	unmarshalerType  = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	rawExtensionType = reflect.TypeOf(runtime.RawExtension{})
+)
+
+// FieldError is a field rejected by a strict decode, Path being its JSON
+// path, e.g. $.spec.tempalte.
+type FieldError struct {
+	Type FieldErrorType
+	Path string
+}
+
+func (e *FieldError) Error() string {
+	if e.Type == FieldErrorDuplicate {
+		return fmt.Sprintf("duplicate field %s", e.Path)
+	}
+
+	return fmt.Sprintf("unknown field %s", e.Path)
+}
+
+// FieldErrors aggregates every field rejected by a strict decode.
+type FieldErrors []*FieldError
+
+func (e FieldErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+
+	return fmt.Sprintf("strict decoding failed: [%s]", strings.Join(messages, ", "))
+}
+
+// duplicateFields lists the keys defined more than once in an object of
+// the json or yaml document. Syntax errors are left to the decoding.
+func duplicateFields(data []byte) FieldErrors {
+	errs := make(FieldErrors, 0)
+
+	if DetectFormat(data) == FormatYAML {
+		// a MapSlice target keeps the duplicates of nested mappings too
+		doc := yaml.MapSlice{}
+		if yaml.Unmarshal(data, &doc) == nil {
+			scanYAMLDuplicates("$", doc, &errs)
+		}
+		return errs
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	scanDuplicates(decoder, "$", &errs)
+
+	return errs
+}
+
+func scanYAMLDuplicates(path string, value interface{}, errs *FieldErrors) {
+	switch v := value.(type) {
+	case yaml.MapSlice:
+		seen := make(map[string]bool)
+		for _, item := range v {
+			key := fmt.Sprint(item.Key)
+			keyPath := JSONPathKey(path, key)
+			if seen[key] {
+				*errs = append(*errs, &FieldError{Type: FieldErrorDuplicate, Path: keyPath})
+			}
+			seen[key] = true
+
+			scanYAMLDuplicates(keyPath, item.Value, errs)
+		}
+	case []interface{}:
+		for i, item := range v {
+			scanYAMLDuplicates(fmt.Sprintf("%s[%d]", path, i), item, errs)
+		}
+	}
+}
+
+func scanDuplicates(decoder *json.Decoder, path string, errs *FieldErrors) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		seen := make(map[string]bool)
+		for decoder.More() {
+			token, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+
+			key := token.(string)
+			keyPath := JSONPathKey(path, key)
+			if seen[key] {
+				*errs = append(*errs, &FieldError{Type: FieldErrorDuplicate, Path: keyPath})
+			}
+			seen[key] = true
+
+			err = scanDuplicates(decoder, keyPath, errs)
+			if err != nil {
+				return err
+			}
+		}
+	case json.Delim('['):
+		for i := 0; decoder.More(); i++ {
+			err := scanDuplicates(decoder, fmt.Sprintf("%s[%d]", path, i), errs)
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		return nil
+	}
+
+	// closing delimiter
+	_, err = decoder.Token()
+	return err
+}
+
+// unknownFields lists the keys of u that the registered type of its kind
+// has no field for. The objects of templates are checked against their own
+// kinds, other nested objects and custom json types are not inspected.
+func (l *Loader) unknownFields(u *unstructured.Unstructured) FieldErrors {
+	errs := make(FieldErrors, 0)
+
+	obj, err := l.Scheme.New(u.GroupVersionKind())
+	if err != nil {
+		return errs
+	}
+
+	l.walkUnknownFields("$", u.Object, reflect.TypeOf(obj), &errs)
+
+	return errs
+}
+
+func (l *Loader) walkUnknownFields(path string, value interface{}, t reflect.Type, errs *FieldErrors) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == rawExtensionType {
+		if nested, ok := value.(map[string]interface{}); ok {
+			errs.appendNested(path, l.unknownFields(&unstructured.Unstructured{Object: nested}))
+		}
+		return
+	}
+
+	if reflect.PtrTo(t).Implements(unmarshalerType) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return
+		}
+
+		fields := jsonFields(t)
+		for _, key := range sortedKeys(m) {
+			keyPath := JSONPathKey(path, key)
+
+			fieldType, ok := fields[key]
+			if !ok {
+				*errs = append(*errs, &FieldError{Type: FieldErrorUnknown, Path: keyPath})
+				continue
+			}
+
+			l.walkUnknownFields(keyPath, m[key], fieldType, errs)
+		}
+	case reflect.Map:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return
+		}
+
+		for _, key := range sortedKeys(m) {
+			l.walkUnknownFields(JSONPathKey(path, key), m[key], t.Elem(), errs)
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := value.([]interface{})
+		if !ok {
+			return
+		}
+
+		for i, item := range items {
+			l.walkUnknownFields(fmt.Sprintf("%s[%d]", path, i), item, t.Elem(), errs)
+		}
+	}
+}
+
+// appendNested adds nested errors, rebasing their $ paths on path.
+func (e *FieldErrors) appendNested(path string, nested FieldErrors) {
+	for _, err := range nested {
+		*e = append(*e, &FieldError{Type: err.Type, Path: path + strings.TrimPrefix(err.Path, "$")})
+	}
+}
+
+// jsonFields maps the json names of the fields of struct t to their types,
+// inlined and embedded structs included.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && name == "" {
+			fieldType := field.Type
+			if fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+
+			if fieldType.Kind() == reflect.Struct {
+				for inlineName, inlineType := range jsonFields(fieldType) {
+					fields[inlineName] = inlineType
+				}
+				continue
+			}
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+
+	return fields
+}
+
+// JSONPathKey appends key to the JSON path, in bracket notation when key is
+// not an identifier, e.g. $.metadata.labels['app.kubernetes.io/name'].
+func JSONPathKey(path, key string) string {
+	if identifierExp.MatchString(key) {
+		return path + "." + key
+	}
+
+	return path + "['" + strings.Replace(key, "'", "\\'", -1) + "']"
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,102 @@
+package kubernetes
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLoadKubernetesResourceFromFileWithOptions_Strict(t *testing.T) {
+	expected := []FieldError{
+		{Type: FieldErrorDuplicate, Path: "$.metadata.labels.app"},
+		{Type: FieldErrorUnknown, Path: "$.objects[0].spec.tempalte"},
+		{Type: FieldErrorUnknown, Path: "$.objects[1].spec.ports[0].protocl"},
+		{Type: FieldErrorUnknown, Path: "$.parameter"},
+	}
+
+	for _, path := range []string{"_testdata/strict-template.yaml", "_testdata/strict-template.json"} {
+		_, err := LoadKubernetesResourceFromFile(path)
+		if err != nil {
+			t.Fatalf("\"%s\" should load without strict decoding: %v", path, err)
+		}
+
+		_, err = LoadKubernetesResourceFromFileWithOptions(path, DecodeStrictOpts)
+
+		errs, ok := err.(FieldErrors)
+		if !ok {
+			t.Fatalf("\"%s\" expected field errors but got %v", path, err)
+		}
+
+		if len(errs) != len(expected) {
+			t.Fatalf("\"%s\" expected %d errors but got %v", path, len(expected), errs)
+		}
+
+		for i, err := range errs {
+			if err.Type != expected[i].Type || err.Path != expected[i].Path {
+				t.Fatalf("\"%s\" expected %s field %s but got %v", path, expected[i].Type, expected[i].Path, err)
+			}
+		}
+	}
+}
+
+func TestLoadKubernetesResourceWithOptions_Strict(t *testing.T) {
+	cases := []struct {
+		Name        string
+		Data        string
+		Opts        DecodeOpt
+		ExpectError string
+	}{
+		{
+			Name: "Should accept known fields",
+			Data: `{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "web", "annotations": {"any.key/name": "value"}}, "spec": {"ports": [{"port": 8080, "targetPort": "http"}]}}`,
+			Opts: DecodeStrictOpts,
+		},
+		{
+			Name:        "Should report quoted paths",
+			Data:        `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "config"}, "data": {"a.key": "1", "a.key": "2"}}`,
+			Opts:        DecodeStrictOpts,
+			ExpectError: "strict decoding failed: [duplicate field $.data['a.key']]",
+		},
+		{
+			Name: "Should not inspect unregistered kinds",
+			Data: `{"apiVersion": "integreatly.org/v1alpha1", "kind": "WebApp", "metadata": {"name": "web"}, "spec": {"anything": "goes"}}`,
+			Opts: DecodeOpt{AllowUnstructured: true, Strict: true},
+		},
+	}
+
+	for _, tc := range cases {
+		_, err := LoadKubernetesResourceWithOptions([]byte(tc.Data), tc.Opts)
+
+		if tc.ExpectError == "" && err != nil {
+			t.Fatalf("\"%s\" did not expect error but got %s", tc.Name, err)
+		}
+
+		if tc.ExpectError != "" && (err == nil || err.Error() != tc.ExpectError) {
+			t.Fatalf("\"%s\" expected error %s but got %v", tc.Name, tc.ExpectError, err)
+		}
+	}
+}
+
+func TestLoadKubernetesResourcesWithOptions_Strict(t *testing.T) {
+	data := `{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "web"}}
+{"apiVersion": "v1", "kind": "List", "items": [
+  {"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "config"}},
+  {"apiVersion": "v1", "kind": "Secret", "metadata": {"name": "secret"}, "strinData": {}}
+]}`
+
+	_, err := LoadKubernetesResourcesWithOptions(strings.NewReader(data), DecodeStrictOpts)
+	if err == nil || err.Error() != "document 1: strict decoding failed: [unknown field $.items[1].strinData]" {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	file, err := os.Open("_testdata/multi-doc.yaml")
+	if err != nil {
+		t.Fatalf("Failed to open mock file: %v", err)
+	}
+	defer file.Close()
+
+	objects, err := LoadKubernetesResourcesWithOptions(file, DecodeStrictOpts)
+	if err != nil || len(objects) != 2 {
+		t.Fatalf("Valid documents should load in strict mode: %v", err)
+	}
+}
@@ -4,75 +4,122 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 
-	"fmt"
-	"io/ioutil"
-	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/apimachinery/pkg/runtime/serializer"
-	"k8s.io/apimachinery/pkg/util/yaml"
-	"strings"
-
 	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
 
 	"github.com/integr8ly/operator-sdk-openshift-utils/pkg/api/schemes"
 )
 
 var (
-	scheme      = runtime.NewScheme()
-	codecs      = serializer.NewCodecFactory(scheme)
-	decoderFunc = decoder
+	scheme = runtime.NewScheme()
+)
+
+// DecodeOpt controls how objects are decoded into typed objects.
+// AllowUnstructured returns objects of kinds that are not registered in the
+// package scheme, e.g. custom resources, as *unstructured.Unstructured
+// instead of failing. Strict fails with FieldErrors on the unknown fields
+// and duplicate keys that are otherwise dropped silently.
+type DecodeOpt struct {
+	AllowUnstructured bool
+	Strict            bool
+}
+
+var (
+	DecodeDefaultOpts = DecodeOpt{AllowUnstructured: false, Strict: false}
+
+	DecodeUnstructuredOpts = DecodeOpt{AllowUnstructured: true, Strict: false}
+
+	DecodeStrictOpts = DecodeOpt{AllowUnstructured: false, Strict: true}
 )
 
 func init() {
 	schemes.AddToScheme(scheme)
 }
 
-func decoder(gv schema.GroupVersion, codecs serializer.CodecFactory) runtime.Decoder {
-	codec := codecs.UniversalDecoder(gv)
-	return codec
+func RuntimeObjectFromUnstructured(u *unstructured.Unstructured) (runtime.Object, error) {
+	return RuntimeObjectFromUnstructuredWithOptions(u, DecodeDefaultOpts)
 }
 
-func RuntimeObjectFromUnstructured(u *unstructured.Unstructured) (runtime.Object, error) {
+func RuntimeObjectFromUnstructuredWithOptions(u *unstructured.Unstructured, opts DecodeOpt) (runtime.Object, error) {
+	return defaultLoader.RuntimeObjectFromUnstructured(u, opts)
+}
+
+func (l *Loader) RuntimeObjectFromUnstructured(u *unstructured.Unstructured, opts DecodeOpt) (runtime.Object, error) {
 	gvk := u.GroupVersionKind()
-	decoder := decoderFunc(gvk.GroupVersion(), codecs)
+	if opts.AllowUnstructured && !gvk.Empty() && !l.Scheme.Recognizes(gvk) {
+		return u.DeepCopy(), nil
+	}
+
+	if opts.Strict {
+		if errs := l.unknownFields(u); len(errs) > 0 {
+			return nil, errs
+		}
+	}
 
-	b, err := u.MarshalJSON()
+	obj, err := l.Scheme.New(gvk)
 	if err != nil {
-		return nil, fmt.Errorf("error running MarshalJSON on unstructured object: %v", err)
+		return nil, fmt.Errorf("failed to convert unstructured object with gvk(%v): %v", gvk.String(), err)
 	}
 
-	ro, _, err := decoder.Decode(b, &gvk, nil)
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, obj)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode json data with gvk(%v): %v", gvk.String(), err)
+		return nil, fmt.Errorf("failed to convert unstructured object with gvk(%v): %v", gvk.String(), err)
 	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
 
-	return ro, nil
+	// same as the codecs, e.g. templates keep their objects as runtime.Unknown
+	if nested, ok := obj.(runtime.NestedObjectDecoder); ok {
+		err = nested.DecodeNestedObjects(unstructured.UnstructuredJSONScheme)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode nested objects with gvk(%v): %v", gvk.String(), err)
+		}
+	}
+	l.Scheme.Default(obj)
+
+	return obj, nil
 }
 
 func isYaml(filename string) bool {
-	for _, suffix := range []string{".yaml", "yaml"} {
-		if strings.HasSuffix(filename, suffix) {
-			return true
-		}
-	}
+	ext := filepath.Ext(filename)
 
-	return false
+	return ext == ".yaml" || ext == ".yml"
 }
 
 func LoadKubernetesResourceFromFile(path string) (runtime.Object, error) {
-	data, err := ioutil.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
+	return LoadKubernetesResourceFromFileWithOptions(path, DecodeDefaultOpts)
+}
+
+func LoadKubernetesResourceFromFileWithOptions(path string, opts DecodeOpt) (runtime.Object, error) {
+	return defaultLoader.LoadKubernetesResourceFromFile(path, opts)
+}
 
-	data, err = JsonIfYaml(data, path)
+func (l *Loader) LoadKubernetesResourceFromFile(path string, opts DecodeOpt) (runtime.Object, error) {
+	file, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
+	defer file.Close()
 
-	return LoadKubernetesResource(data)
+	return l.DecodeAny(file, opts)
 }
 
 func LoadKubernetesResource(jsonData []byte) (runtime.Object, error) {
+	return LoadKubernetesResourceWithOptions(jsonData, DecodeDefaultOpts)
+}
+
+func LoadKubernetesResourceWithOptions(jsonData []byte, opts DecodeOpt) (runtime.Object, error) {
+	return defaultLoader.LoadKubernetesResource(jsonData, opts)
+}
+
+func (l *Loader) LoadKubernetesResource(jsonData []byte, opts DecodeOpt) (runtime.Object, error) {
+	return l.decode(jsonData, jsonData, opts)
+}
+
+// decode converts jsonData, checking source, the data as it was read, for
+// duplicate keys in strict mode.
+func (l *Loader) decode(source, jsonData []byte, opts DecodeOpt) (runtime.Object, error) {
 	u := unstructured.Unstructured{}
 
 	err := u.UnmarshalJSON(jsonData)
@@ -80,25 +127,120 @@ func LoadKubernetesResource(jsonData []byte) (runtime.Object, error) {
 		return nil, err
 	}
 
-	return RuntimeObjectFromUnstructured(&u)
-}
+	if !opts.Strict {
+		return l.RuntimeObjectFromUnstructured(&u, opts)
+	}
 
-func JsonIfYaml(source []byte, filename string) ([]byte, error) {
-	if isYaml(filename) {
-		return yaml.ToJSON(source)
+	errs := duplicateFields(source)
+	obj, err := l.RuntimeObjectFromUnstructured(&u, opts)
+	if unknown, ok := err.(FieldErrors); ok {
+		errs = append(errs, unknown...)
+	} else if err != nil {
+		return nil, err
 	}
 
-	return source, nil
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return obj, nil
+}
+
+// IsUnstructured reports whether obj was left unstructured by a decode with
+// AllowUnstructured rather than decoded into a registered type.
+func IsUnstructured(obj runtime.Object) bool {
+	_, ok := obj.(*unstructured.Unstructured)
+	return ok
+}
+
+// JsonIfYaml returns source as json. The format is detected from the
+// content, filename is only kept for compatibility.
+func JsonIfYaml(source []byte, filename string) ([]byte, error) {
+	return AsJSON(source)
 }
 
+// UnstructuredFromRuntimeObject returns a copy of ro as unstructured, with
+// numbers as float64 like a json decoding into interface{} values.
 func UnstructuredFromRuntimeObject(ro runtime.Object) (*unstructured.Unstructured, error) {
-	b, err := json.Marshal(ro)
+	if u, ok := ro.(runtime.Unstructured); ok {
+		content := u.UnstructuredContent()
+		if isJSONValue(content) {
+			return &unstructured.Unstructured{Object: float64Numbers(runtime.DeepCopyJSON(content))}, nil
+		}
+
+		// content built in go, e.g. holding int or map[string]string values
+		b, err := json.Marshal(content)
+		if err != nil {
+			return nil, fmt.Errorf("error running MarshalJSON on unstructured object: %v", err)
+		}
+
+		object := make(map[string]interface{})
+		err = json.Unmarshal(b, &object)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal json into unstructured object: %v", err)
+		}
+
+		return &unstructured.Unstructured{Object: object}, nil
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(ro)
 	if err != nil {
-		return nil, fmt.Errorf("error running MarshalJSON on runtime object: %v", err)
+		return nil, fmt.Errorf("failed to convert runtime object to unstructured: %v", err)
+	}
+
+	return &unstructured.Unstructured{Object: float64Numbers(content)}, nil
+}
+
+// float64Numbers converts the int64 and json.Number values of content to
+// float64 in place.
+func float64Numbers(content map[string]interface{}) map[string]interface{} {
+	for key, value := range content {
+		content[key] = float64Number(value)
+	}
+
+	return content
+}
+
+func float64Number(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return float64Numbers(v)
+	case []interface{}:
+		for i, item := range v {
+			v[i] = float64Number(item)
+		}
+	case int64:
+		return float64(v)
+	case json.Number:
+		f, err := v.Float64()
+		if err == nil {
+			return f
+		}
 	}
-	var u unstructured.Unstructured
-	if err := json.Unmarshal(b, &u.Object); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal json into unstructured object: %v", err)
+
+	return value
+}
+
+// isJSONValue reports whether runtime.DeepCopyJSON can copy value.
+func isJSONValue(value interface{}) bool {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, item := range v {
+			if !isJSONValue(item) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		for _, item := range v {
+			if !isJSONValue(item) {
+				return false
+			}
+		}
+		return true
+	case string, int64, bool, float64, nil, json.Number:
+		return true
 	}
-	return &u, nil
+
+	return false
 }
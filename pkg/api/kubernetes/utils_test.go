@@ -1,7 +1,15 @@
 package kubernetes
 
 import (
+	"encoding/json"
 	"io/ioutil"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"os"
+	"reflect"
 	"testing"
 )
 
@@ -140,3 +148,284 @@ func TestJsonIfYaml(t *testing.T) {
 		}
 	}
 }
+
+func TestLoadKubernetesResourceWithOptions(t *testing.T) {
+	cases := []struct {
+		Name         string
+		Data         string
+		Opts         DecodeOpt
+		Unstructured bool
+		ExpectError  bool
+	}{
+		{
+			Name:         "Should decode registered kinds",
+			Data:         `{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "web"}}`,
+			Opts:         DecodeUnstructuredOpts,
+			Unstructured: false,
+			ExpectError:  false,
+		},
+		{
+			Name:         "Should keep unknown kinds unstructured",
+			Data:         `{"apiVersion": "integreatly.org/v1alpha1", "kind": "WebApp", "metadata": {"name": "web"}}`,
+			Opts:         DecodeUnstructuredOpts,
+			Unstructured: true,
+			ExpectError:  false,
+		},
+		{
+			Name:        "Should fail on unknown kinds in strict mode",
+			Data:        `{"apiVersion": "integreatly.org/v1alpha1", "kind": "WebApp", "metadata": {"name": "web"}}`,
+			Opts:        DecodeDefaultOpts,
+			ExpectError: true,
+		},
+		{
+			Name:        "Should fail on objects without a kind",
+			Data:        `{"metadata": {"name": "web"}}`,
+			Opts:        DecodeUnstructuredOpts,
+			ExpectError: true,
+		},
+	}
+
+	for _, tc := range cases {
+		obj, err := LoadKubernetesResourceWithOptions([]byte(tc.Data), tc.Opts)
+
+		if tc.ExpectError {
+			if err == nil {
+				t.Fatalf("\"%s\" expected an error but got none", tc.Name)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("\"%s\" did not expect error but got %s", tc.Name, err)
+		}
+
+		if IsUnstructured(obj) != tc.Unstructured {
+			t.Fatalf("\"%s\" expected unstructured to be %v but got %T", tc.Name, tc.Unstructured, obj)
+		}
+	}
+}
+
+func TestIsYaml(t *testing.T) {
+	cases := []struct {
+		Filename string
+		Expected bool
+	}{
+		{Filename: "template.yaml", Expected: true},
+		{Filename: "template.yml", Expected: true},
+		{Filename: "template.json", Expected: false},
+		{Filename: "notyaml", Expected: false},
+	}
+
+	for _, tc := range cases {
+		if isYaml(tc.Filename) != tc.Expected {
+			t.Fatalf("\"%s\" expected %v", tc.Filename, tc.Expected)
+		}
+	}
+}
+
+// jsonRuntimeObjectFromUnstructured and jsonUnstructuredFromRuntimeObject
+// are the json round trips the converter based functions replaced, kept as
+// a reference for their semantics and performance.
+func jsonRuntimeObjectFromUnstructured(u *unstructured.Unstructured) (runtime.Object, error) {
+	gvk := u.GroupVersionKind()
+
+	b, err := u.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	obj, _, err := serializer.NewCodecFactory(scheme).UniversalDecoder(gvk.GroupVersion()).Decode(b, &gvk, nil)
+	return obj, err
+}
+
+func jsonUnstructuredFromRuntimeObject(ro runtime.Object) (*unstructured.Unstructured, error) {
+	b, err := json.Marshal(ro)
+	if err != nil {
+		return nil, err
+	}
+
+	u := &unstructured.Unstructured{}
+	err = json.Unmarshal(b, &u.Object)
+	return u, err
+}
+
+func loadConversionObjects(t testing.TB) []*unstructured.Unstructured {
+	objects := make([]*unstructured.Unstructured, 0)
+	for _, path := range []string{"_testdata/multi-doc.yaml", "_testdata/list.json", "_testdata/test-template.json"} {
+		file, err := os.Open(path)
+		if err != nil {
+			t.Fatalf("Failed to open mock file: %v", err)
+		}
+
+		loaded, err := LoadKubernetesResources(file)
+		file.Close()
+		if err != nil {
+			t.Fatalf("Failed to load %s: %v", path, err)
+		}
+
+		for _, obj := range loaded {
+			u, err := jsonUnstructuredFromRuntimeObject(obj)
+			if err != nil {
+				t.Fatalf("Failed to convert %s: %v", path, err)
+			}
+			objects = append(objects, u)
+		}
+	}
+
+	return objects
+}
+
+func TestConversion_MatchesJSON(t *testing.T) {
+	for _, u := range loadConversionObjects(t) {
+		expected, err := jsonRuntimeObjectFromUnstructured(u)
+		if err != nil {
+			t.Fatalf("Test failed: %v", err)
+		}
+
+		obj, err := RuntimeObjectFromUnstructured(u)
+		if err != nil {
+			t.Fatalf("Test failed: %v", err)
+		}
+
+		if !equality.Semantic.DeepEqual(obj, expected) {
+			t.Fatalf("%s %s differs from the json decoding:\n%#v\n%#v", u.GetKind(), u.GetName(), obj, expected)
+		}
+
+		expectedU, err := jsonUnstructuredFromRuntimeObject(obj)
+		if err != nil {
+			t.Fatalf("Test failed: %v", err)
+		}
+
+		converted, err := UnstructuredFromRuntimeObject(obj)
+		if err != nil {
+			t.Fatalf("Test failed: %v", err)
+		}
+
+		if !reflect.DeepEqual(converted.Object, expectedU.Object) {
+			t.Fatalf("%s %s differs from the json encoding:\n%v\n%v", u.GetKind(), u.GetName(), converted.Object, expectedU.Object)
+		}
+	}
+}
+
+func TestUnstructuredFromRuntimeObject_GoValues(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"spec": map[string]interface{}{
+			"replicas": 3,
+			"selector": map[string]string{"app": "web"},
+		},
+	}}
+
+	u, err := UnstructuredFromRuntimeObject(obj)
+	if err != nil {
+		t.Fatalf("Test failed: %v", err)
+	}
+
+	value, ok, _ := unstructured.NestedFloat64(u.Object, "spec", "replicas")
+	if !ok || value != 3 {
+		t.Fatalf("Replicas should be a float64: %v", u.Object["spec"])
+	}
+
+	selector, ok, _ := unstructured.NestedStringMap(u.Object, "spec", "selector")
+	if !ok || selector["app"] != "web" {
+		t.Fatalf("Unexpected selector: %v", u.Object["spec"])
+	}
+}
+
+func TestUnstructuredFromRuntimeObject_Numbers(t *testing.T) {
+	replicas := int32(3)
+	typed := &appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Replicas:                &replicas,
+			ProgressDeadlineSeconds: &replicas,
+		},
+	}
+
+	decoded := &unstructured.Unstructured{}
+	err := decoded.UnmarshalJSON([]byte(`{"apiVersion": "v1", "kind": "Service", "spec": {"replicas": 3}}`))
+	if err != nil {
+		t.Fatalf("Test failed: %v", err)
+	}
+
+	for _, obj := range []runtime.Object{typed, decoded} {
+		u, err := UnstructuredFromRuntimeObject(obj)
+		if err != nil {
+			t.Fatalf("Test failed: %v", err)
+		}
+
+		value, ok, _ := unstructured.NestedFloat64(u.Object, "spec", "replicas")
+		if !ok || value != 3 {
+			t.Fatalf("Replicas should be a float64: %v", u.Object["spec"])
+		}
+	}
+
+	if _, ok := decoded.Object["spec"].(map[string]interface{})["replicas"].(int64); !ok {
+		t.Fatalf("Source object should not be modified: %v", decoded.Object)
+	}
+}
+
+func BenchmarkRuntimeObjectFromUnstructured(b *testing.B) {
+	objects := loadConversionObjects(b)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, u := range objects {
+			if _, err := RuntimeObjectFromUnstructured(u); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkRuntimeObjectFromUnstructured_JSON(b *testing.B) {
+	objects := loadConversionObjects(b)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, u := range objects {
+			if _, err := jsonRuntimeObjectFromUnstructured(u); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func loadTypedConversionObjects(b *testing.B) []runtime.Object {
+	objects := make([]runtime.Object, 0)
+	for _, u := range loadConversionObjects(b) {
+		obj, err := RuntimeObjectFromUnstructured(u)
+		if err != nil {
+			b.Fatal(err)
+		}
+		objects = append(objects, obj)
+	}
+
+	return objects
+}
+
+func BenchmarkUnstructuredFromRuntimeObject(b *testing.B) {
+	objects := loadTypedConversionObjects(b)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, obj := range objects {
+			if _, err := UnstructuredFromRuntimeObject(obj); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkUnstructuredFromRuntimeObject_JSON(b *testing.B) {
+	objects := loadTypedConversionObjects(b)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, obj := range objects {
+			if _, err := jsonUnstructuredFromRuntimeObject(obj); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package template
+
+import (
+	"fmt"
+	"k8s.io/apimachinery/pkg/runtime"
+	"reflect"
+)
+
+//go:generate go run accessors_generate.go
+
+// ObjectsOfType appends a deep copy of every object of the slice element
+// type to list, which must be a pointer to a slice of pointers to a type
+// registered in schemes, e.g. *[]*appsv1.DeploymentConfig.
+func (t *Tmpl) ObjectsOfType(list interface{}) error {
+	value := reflect.ValueOf(list)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("expected a pointer to a slice, got %T", list)
+	}
+
+	slice := value.Elem()
+	elemType := slice.Type().Elem()
+	if !elemType.Implements(reflect.TypeOf((*runtime.Object)(nil)).Elem()) {
+		return fmt.Errorf("expected a slice of runtime objects, got %T", list)
+	}
+
+	for _, obj := range t.Objects {
+		if reflect.TypeOf(obj) == elemType {
+			slice = reflect.Append(slice, reflect.ValueOf(obj.DeepCopyObject()))
+		}
+	}
+
+	value.Elem().Set(slice)
+
+	return nil
+}
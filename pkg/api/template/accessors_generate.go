@@ -0,0 +1,140 @@
+//go:build ignore
+// +build ignore
+
+// Generates the typed object accessors of Tmpl for the kinds listed in
+// accessors, which must be registered in schemes. Run with go generate.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/integr8ly/operator-sdk-openshift-utils/pkg/api/schemes"
+	"go/format"
+	"io/ioutil"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const output = "zz_generated.accessors.go"
+
+type accessor struct {
+	gvk    schema.GroupVersionKind
+	method string
+}
+
+// accessors lists the kinds that can be part of a template. Accessors are
+// named after the plural of the kind unless a method name is given.
+var accessors = []accessor{
+	{gvk: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}},
+	{gvk: schema.GroupVersionKind{Version: "v1", Kind: "Endpoints"}, method: "Endpoints"},
+	{gvk: schema.GroupVersionKind{Version: "v1", Kind: "LimitRange"}},
+	{gvk: schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}},
+	{gvk: schema.GroupVersionKind{Version: "v1", Kind: "PersistentVolume"}},
+	{gvk: schema.GroupVersionKind{Version: "v1", Kind: "PersistentVolumeClaim"}},
+	{gvk: schema.GroupVersionKind{Version: "v1", Kind: "Pod"}},
+	{gvk: schema.GroupVersionKind{Version: "v1", Kind: "ReplicationController"}},
+	{gvk: schema.GroupVersionKind{Version: "v1", Kind: "ResourceQuota"}},
+	{gvk: schema.GroupVersionKind{Version: "v1", Kind: "Secret"}},
+	{gvk: schema.GroupVersionKind{Version: "v1", Kind: "Service"}},
+	{gvk: schema.GroupVersionKind{Version: "v1", Kind: "ServiceAccount"}},
+	{gvk: schema.GroupVersionKind{Group: "apps.openshift.io", Version: "v1", Kind: "DeploymentConfig"}},
+	{gvk: schema.GroupVersionKind{Group: "authorization.openshift.io", Version: "v1", Kind: "ClusterRole"}, method: "OpenShiftClusterRoles"},
+	{gvk: schema.GroupVersionKind{Group: "authorization.openshift.io", Version: "v1", Kind: "ClusterRoleBinding"}, method: "OpenShiftClusterRoleBindings"},
+	{gvk: schema.GroupVersionKind{Group: "authorization.openshift.io", Version: "v1", Kind: "Role"}, method: "OpenShiftRoles"},
+	{gvk: schema.GroupVersionKind{Group: "authorization.openshift.io", Version: "v1", Kind: "RoleBinding"}, method: "OpenShiftRoleBindings"},
+	{gvk: schema.GroupVersionKind{Group: "authorization.openshift.io", Version: "v1", Kind: "RoleBindingRestriction"}},
+	{gvk: schema.GroupVersionKind{Group: "build.openshift.io", Version: "v1", Kind: "Build"}},
+	{gvk: schema.GroupVersionKind{Group: "build.openshift.io", Version: "v1", Kind: "BuildConfig"}},
+	{gvk: schema.GroupVersionKind{Group: "extensions", Version: "v1beta1", Kind: "DaemonSet"}},
+	{gvk: schema.GroupVersionKind{Group: "extensions", Version: "v1beta1", Kind: "Deployment"}},
+	{gvk: schema.GroupVersionKind{Group: "extensions", Version: "v1beta1", Kind: "Ingress"}},
+	{gvk: schema.GroupVersionKind{Group: "extensions", Version: "v1beta1", Kind: "NetworkPolicy"}},
+	{gvk: schema.GroupVersionKind{Group: "extensions", Version: "v1beta1", Kind: "PodSecurityPolicy"}},
+	{gvk: schema.GroupVersionKind{Group: "extensions", Version: "v1beta1", Kind: "ReplicaSet"}},
+	{gvk: schema.GroupVersionKind{Group: "image.openshift.io", Version: "v1", Kind: "ImageStream"}},
+	{gvk: schema.GroupVersionKind{Group: "image.openshift.io", Version: "v1", Kind: "ImageStreamTag"}},
+	{gvk: schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"}},
+	{gvk: schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRoleBinding"}},
+	{gvk: schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "Role"}},
+	{gvk: schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "RoleBinding"}},
+	{gvk: schema.GroupVersionKind{Group: "route.openshift.io", Version: "v1", Kind: "Route"}},
+}
+
+func main() {
+	scheme := runtime.NewScheme()
+	err := schemes.AddToScheme(scheme)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	imports := make(map[string]string)
+	body := &bytes.Buffer{}
+
+	for _, a := range accessors {
+		obj, err := scheme.New(a.gvk)
+		if err != nil {
+			log.Fatalf("%s is not registered in schemes: %v", a.gvk, err)
+		}
+
+		typ := reflect.TypeOf(obj).Elem()
+		alias := importAlias(a.gvk.GroupVersion())
+		imports[alias] = typ.PkgPath()
+
+		method := a.method
+		if method == "" {
+			method = plural(a.gvk.Kind)
+		}
+
+		fmt.Fprintf(body, "\n// %s returns a deep copy of the %s %s objects.\n", method, a.gvk.GroupVersion(), a.gvk.Kind)
+		fmt.Fprintf(body, "func (t *Tmpl) %s() []*%s.%s {\n", method, alias, typ.Name())
+		fmt.Fprintf(body, "\tobjects := make([]*%s.%s, 0)\n", alias, typ.Name())
+		fmt.Fprintf(body, "\tt.ObjectsOfType(&objects)\n\n\treturn objects\n}\n")
+	}
+
+	aliases := make([]string, 0, len(imports))
+	for alias := range imports {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	src := &bytes.Buffer{}
+	fmt.Fprintf(src, "// Code generated by accessors_generate.go. DO NOT EDIT.\n\npackage template\n\nimport (\n")
+	for _, alias := range aliases {
+		fmt.Fprintf(src, "\t%s %q\n", alias, imports[alias])
+	}
+	fmt.Fprintf(src, ")\n")
+	src.Write(body.Bytes())
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = ioutil.WriteFile(output, formatted, 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func importAlias(gv schema.GroupVersion) string {
+	if gv.Group == "" {
+		return "core" + gv.Version
+	}
+
+	return strings.Split(gv.Group, ".")[0] + gv.Version
+}
+
+func plural(kind string) string {
+	switch {
+	case strings.HasSuffix(kind, "y"):
+		return strings.TrimSuffix(kind, "y") + "ies"
+	case strings.HasSuffix(kind, "s"):
+		return kind + "es"
+	}
+
+	return kind + "s"
+}
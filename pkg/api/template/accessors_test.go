@@ -0,0 +1,85 @@
+package template
+
+import (
+	appsv1 "github.com/openshift/api/apps/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	"testing"
+)
+
+func TestTmpl_ObjectsOfType(t *testing.T) {
+	tmpl := &Tmpl{Objects: loadObjects(t, "_testdata/template-params.json")}
+
+	cases := []struct {
+		Name        string
+		List        interface{}
+		Validate    func(list interface{})
+		ExpectError bool
+	}{
+		{
+			Name: "Should return the objects of a registered type",
+			List: &[]*routev1.Route{},
+			Validate: func(list interface{}) {
+				routes := *list.(*[]*routev1.Route)
+				if len(routes) != 1 || routes[0].Name != "params-app" {
+					t.Fatalf("Failed to get routes: %v", routes)
+				}
+			},
+			ExpectError: false,
+		},
+		{
+			Name: "Should return an empty list when there are no objects of the type",
+			List: &[]*corev1.Secret{},
+			Validate: func(list interface{}) {
+				if len(*list.(*[]*corev1.Secret)) != 0 {
+					t.Fatalf("Secrets should be empty: %v", list)
+				}
+			},
+			ExpectError: false,
+		},
+		{
+			Name:        "Should fail when the list is not a pointer to a slice",
+			List:        []*routev1.Route{},
+			Validate:    func(list interface{}) {},
+			ExpectError: true,
+		},
+		{
+			Name:        "Should fail when the slice elements are not runtime objects",
+			List:        &[]routev1.Route{},
+			Validate:    func(list interface{}) {},
+			ExpectError: true,
+		},
+	}
+
+	for _, tc := range cases {
+		err := tmpl.ObjectsOfType(tc.List)
+
+		if tc.ExpectError && err == nil {
+			t.Fatalf("\"%s\" expected an error but got none", tc.Name)
+		}
+
+		if !tc.ExpectError && err != nil {
+			t.Fatalf("\"%s\" did not expect error but got %s", tc.Name, err)
+		}
+
+		tc.Validate(tc.List)
+	}
+}
+
+func TestTmpl_DeploymentConfigs(t *testing.T) {
+	tmpl := &Tmpl{Objects: loadObjects(t, "_testdata/template-params.json")}
+
+	dcs := tmpl.DeploymentConfigs()
+	if len(dcs) != 1 {
+		t.Fatalf("Failed to get deployment configs: %v", dcs)
+	}
+
+	dcs[0].Spec.Replicas = 10
+	if tmpl.Objects[0].(*appsv1.DeploymentConfig).Spec.Replicas == 10 {
+		t.Fatalf("Deployment configs should be deep copies")
+	}
+
+	if len(tmpl.Services()) != 1 || len(tmpl.Routes()) != 1 || len(tmpl.ConfigMaps()) != 0 {
+		t.Fatalf("Unexpected typed objects: %v", tmpl.Objects)
+	}
+}
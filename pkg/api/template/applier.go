@@ -0,0 +1,519 @@
+package template
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/integr8ly/operator-sdk-openshift-utils/pkg/api/kubernetes"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"reflect"
+)
+
+type ApplyAction string
+
+const (
+	ApplyCreated   ApplyAction = "Created"
+	ApplyUpdated   ApplyAction = "Updated"
+	ApplyUnchanged ApplyAction = "Unchanged"
+	ApplyDeleted   ApplyAction = "Deleted"
+	ApplyFailed    ApplyAction = "Failed"
+)
+
+var (
+	// serverFields are populated by the api server and kept on update when
+	// the rendered object does not set them.
+	serverFields = map[string][][]string{
+		"Service":               {{"spec", "clusterIP"}, {"spec", "healthCheckNodePort"}},
+		"Route":                 {{"spec", "host"}},
+		"ServiceAccount":        {{"secrets"}, {"imagePullSecrets"}},
+		"PersistentVolumeClaim": {{"spec", "volumeName"}, {"spec", "storageClassName"}},
+	}
+
+	// immutableFields can not be changed once the object exists, so the
+	// existing value always wins on update.
+	immutableFields = map[string][][]string{
+		"Deployment":  {{"spec", "selector"}},
+		"StatefulSet": {{"spec", "selector"}},
+		"DaemonSet":   {{"spec", "selector"}},
+		"ReplicaSet":  {{"spec", "selector"}},
+		"Job":         {{"spec", "selector"}, {"spec", "template"}},
+	}
+
+	// listMergers keep the server and controller owned values set inside
+	// lists, which the field paths above can not address.
+	listMergers = map[string]func(existing, merged *unstructured.Unstructured) error{
+		"Service":          mergeNodePorts,
+		"DeploymentConfig": mergeTriggeredImages,
+	}
+)
+
+// Client is the subset of the controller-runtime client used to apply
+// objects. A controller-runtime client satisfies it as is, and
+// NewDynamicClient adapts a client-go dynamic client to it.
+type Client interface {
+	Get(ctx context.Context, key types.NamespacedName, obj runtime.Object) error
+	Create(ctx context.Context, obj runtime.Object) error
+	Update(ctx context.Context, obj runtime.Object) error
+}
+
+type ApplyResult struct {
+	GroupVersionKind schema.GroupVersionKind
+	Namespace        string
+	Name             string
+	Action           ApplyAction
+	Err              error
+}
+
+type ApplyReport []ApplyResult
+
+// Applier creates the objects missing from the cluster and updates the
+// existing ones, keeping the fields populated by the server.
+type Applier struct {
+	Client    Client
+	Namespace string
+}
+
+func NewApplier(client Client, ns string) *Applier {
+	return &Applier{
+		Client:    client,
+		Namespace: ns,
+	}
+}
+
+// Apply applies every object and reports what was done with each one. The
+// returned error aggregates the errors of the failed objects.
+func (a *Applier) Apply(ctx context.Context, objects []runtime.Object) (ApplyReport, error) {
+	report := make(ApplyReport, 0, len(objects))
+	errs := make([]error, 0)
+
+	for _, obj := range objects {
+		result := a.apply(ctx, obj)
+		if result.Err != nil {
+			errs = append(errs, result.Err)
+		}
+
+		report = append(report, result)
+	}
+
+	return report, utilerrors.NewAggregate(errs)
+}
+
+func (a *Applier) apply(ctx context.Context, obj runtime.Object) ApplyResult {
+	result := ApplyResult{
+		GroupVersionKind: obj.GetObjectKind().GroupVersionKind(),
+		Action:           ApplyFailed,
+	}
+
+	desired, err := kubernetes.UnstructuredFromRuntimeObject(obj)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	if desired.GetNamespace() == "" {
+		desired.SetNamespace(a.Namespace)
+	}
+	result.Namespace = desired.GetNamespace()
+	result.Name = desired.GetName()
+
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(desired.GroupVersionKind())
+
+	err = a.Client.Get(ctx, types.NamespacedName{Namespace: result.Namespace, Name: result.Name}, existing)
+	if errors.IsNotFound(err) {
+		err = a.Client.Create(ctx, desired)
+		if err != nil {
+			result.Err = fmt.Errorf("failed to create %s %s/%s: %v", result.GroupVersionKind.Kind, result.Namespace, result.Name, err)
+			return result
+		}
+
+		result.Action = ApplyCreated
+		return result
+	}
+	if err != nil {
+		result.Err = fmt.Errorf("failed to get %s %s/%s: %v", result.GroupVersionKind.Kind, result.Namespace, result.Name, err)
+		return result
+	}
+
+	merged, err := mergeExisting(desired, existing)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	if isSubset(merged.Object, existing.Object) {
+		result.Action = ApplyUnchanged
+		return result
+	}
+
+	merged.SetResourceVersion(existing.GetResourceVersion())
+	err = a.Client.Update(ctx, merged)
+	if err != nil {
+		result.Err = fmt.Errorf("failed to update %s %s/%s: %v", result.GroupVersionKind.Kind, result.Namespace, result.Name, err)
+		return result
+	}
+
+	result.Action = ApplyUpdated
+	return result
+}
+
+func mergeExisting(desired, existing *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	merged := desired.DeepCopy()
+	kind := desired.GetKind()
+
+	// the status is owned by the server, typed objects only carry its zero
+	// value
+	delete(merged.Object, "status")
+	if status, ok := existing.Object["status"]; ok {
+		merged.Object["status"] = runtime.DeepCopyJSONValue(status)
+	}
+
+	for _, path := range serverFields[kind] {
+		value, found, _ := unstructured.NestedFieldNoCopy(merged.Object, path...)
+		if found && !isEmptyValue(value) {
+			continue
+		}
+
+		err := copyField(existing, merged, path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, path := range immutableFields[kind] {
+		err := copyField(existing, merged, path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if merge, ok := listMergers[kind]; ok {
+		err := merge(existing, merged)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	merged.SetLabels(mergeMaps(existing.GetLabels(), desired.GetLabels()))
+	merged.SetAnnotations(mergeMaps(existing.GetAnnotations(), desired.GetAnnotations()))
+
+	// finalizers and owner references are added by other controllers, e.g.
+	// the pvc protection, and would be removed by the update
+	if finalizers := mergeFinalizers(existing.GetFinalizers(), desired.GetFinalizers()); len(finalizers) > 0 {
+		merged.SetFinalizers(finalizers)
+	}
+	if refs := mergeOwnerReferences(existing.GetOwnerReferences(), desired.GetOwnerReferences()); len(refs) > 0 {
+		merged.SetOwnerReferences(refs)
+	}
+
+	return merged, nil
+}
+
+func mergeFinalizers(existing, desired []string) []string {
+	merged := append([]string{}, existing...)
+	for _, finalizer := range desired {
+		if !containsString(merged, finalizer) {
+			merged = append(merged, finalizer)
+		}
+	}
+
+	return merged
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mergeOwnerReferences adds the desired references to the existing ones,
+// keeping their order so unchanged objects are not updated. An object has
+// a single controller, so an existing controller reference is dropped when
+// the desired object sets another one.
+func mergeOwnerReferences(existing, desired []metav1.OwnerReference) []metav1.OwnerReference {
+	var controller *metav1.OwnerReference
+	for i, ref := range desired {
+		if ref.Controller != nil && *ref.Controller {
+			controller = &desired[i]
+		}
+	}
+
+	merged := make([]metav1.OwnerReference, 0, len(existing)+len(desired))
+	for _, ref := range existing {
+		if index := ownerReferenceIndex(desired, ref.UID); index >= 0 {
+			ref = desired[index]
+		} else if controller != nil && ref.Controller != nil && *ref.Controller {
+			continue
+		}
+		merged = append(merged, ref)
+	}
+
+	for _, ref := range desired {
+		if ownerReferenceIndex(merged, ref.UID) < 0 {
+			merged = append(merged, ref)
+		}
+	}
+
+	return merged
+}
+
+func ownerReferenceIndex(refs []metav1.OwnerReference, uid types.UID) int {
+	for i, ref := range refs {
+		if ref.UID == uid {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// mergeNodePorts keeps the node ports allocated by the server for the
+// ports that do not set one. Ports are matched by name, or by port and
+// protocol when they are unnamed.
+func mergeNodePorts(existing, merged *unstructured.Unstructured) error {
+	serviceType, _, _ := unstructured.NestedString(merged.Object, "spec", "type")
+	if serviceType != "NodePort" && serviceType != "LoadBalancer" {
+		return nil
+	}
+
+	existingPorts, _, _ := unstructured.NestedSlice(existing.Object, "spec", "ports")
+	ports, found, err := unstructured.NestedSlice(merged.Object, "spec", "ports")
+	if err != nil || !found {
+		return err
+	}
+
+	for _, item := range ports {
+		port, ok := item.(map[string]interface{})
+		if !ok || !isEmptyValue(port["nodePort"]) {
+			continue
+		}
+
+		for _, existingItem := range existingPorts {
+			existingPort, ok := existingItem.(map[string]interface{})
+			if ok && portKey(existingPort) == portKey(port) && existingPort["nodePort"] != nil {
+				port["nodePort"] = existingPort["nodePort"]
+			}
+		}
+	}
+
+	return unstructured.SetNestedSlice(merged.Object, ports, "spec", "ports")
+}
+
+func portKey(port map[string]interface{}) string {
+	if name, ok := port["name"].(string); ok && name != "" {
+		return name
+	}
+
+	protocol, ok := port["protocol"].(string)
+	if !ok || protocol == "" {
+		protocol = "TCP"
+	}
+	number, _ := toFloat(port["port"])
+
+	return fmt.Sprintf("%v/%s", number, protocol)
+}
+
+// mergeTriggeredImages keeps the images resolved by the image change
+// triggers of a DeploymentConfig, along with the last triggered image.
+// Reverting them to the template values would redeploy on every apply.
+func mergeTriggeredImages(existing, merged *unstructured.Unstructured) error {
+	triggers, _, err := unstructured.NestedSlice(merged.Object, "spec", "triggers")
+	if err != nil {
+		return err
+	}
+	existingTriggers, _, _ := unstructured.NestedSlice(existing.Object, "spec", "triggers")
+
+	triggered := make(map[string]bool)
+	for _, item := range triggers {
+		trigger, ok := item.(map[string]interface{})
+		if !ok || trigger["type"] != "ImageChange" {
+			continue
+		}
+
+		names, _, _ := unstructured.NestedStringSlice(trigger, "imageChangeParams", "containerNames")
+		for _, name := range names {
+			triggered[name] = true
+		}
+
+		if _, found, _ := unstructured.NestedFieldNoCopy(trigger, "imageChangeParams", "lastTriggeredImage"); found {
+			continue
+		}
+		from, _, _ := unstructured.NestedFieldNoCopy(trigger, "imageChangeParams", "from")
+		for _, existingItem := range existingTriggers {
+			existingTrigger, ok := existingItem.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			existingFrom, _, _ := unstructured.NestedFieldNoCopy(existingTrigger, "imageChangeParams", "from")
+			image, found, _ := unstructured.NestedString(existingTrigger, "imageChangeParams", "lastTriggeredImage")
+			if found && reflect.DeepEqual(from, existingFrom) {
+				unstructured.SetNestedField(trigger, image, "imageChangeParams", "lastTriggeredImage")
+			}
+		}
+	}
+
+	if len(triggered) == 0 {
+		return nil
+	}
+
+	err = unstructured.SetNestedSlice(merged.Object, triggers, "spec", "triggers")
+	if err != nil {
+		return err
+	}
+
+	for _, field := range []string{"containers", "initContainers"} {
+		path := []string{"spec", "template", "spec", field}
+
+		existingContainers, _, _ := unstructured.NestedSlice(existing.Object, path...)
+		images := make(map[string]interface{})
+		for _, item := range existingContainers {
+			if container, ok := item.(map[string]interface{}); ok && !isEmptyValue(container["image"]) {
+				images[fmt.Sprint(container["name"])] = container["image"]
+			}
+		}
+
+		containers, found, err := unstructured.NestedSlice(merged.Object, path...)
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+
+		for _, item := range containers {
+			container, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			name := fmt.Sprint(container["name"])
+			if image, ok := images[name]; ok && triggered[name] {
+				container["image"] = image
+			}
+		}
+
+		err = unstructured.SetNestedSlice(merged.Object, containers, path...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func copyField(from, to *unstructured.Unstructured, path []string) error {
+	value, found, err := unstructured.NestedFieldCopy(from.Object, path...)
+	if err != nil || !found {
+		return err
+	}
+
+	return unstructured.SetNestedField(to.Object, value, path...)
+}
+
+func isEmptyValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+
+	return false
+}
+
+func mergeMaps(existing, desired map[string]string) map[string]string {
+	if len(existing) == 0 && len(desired) == 0 {
+		return nil
+	}
+
+	merged := make(map[string]string)
+	for key, value := range existing {
+		merged[key] = value
+	}
+	for key, value := range desired {
+		merged[key] = value
+	}
+
+	return merged
+}
+
+// isSubset reports whether every field set in desired has the same value
+// in existing, ignoring the fields defaulted by the server. Typed objects
+// can not tell an unset field from a zero one, so zero values in desired,
+// e.g. the targetPort of a Service port, are considered unset.
+func isSubset(desired, existing interface{}) bool {
+	if isZeroValue(desired) {
+		return true
+	}
+	if existing == nil {
+		return false
+	}
+
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		e, ok := existing.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for key, value := range d {
+			if value == nil {
+				continue
+			}
+			if !isSubset(value, e[key]) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		e, ok := existing.([]interface{})
+		if !ok || len(d) != len(e) {
+			return false
+		}
+		for i := range d {
+			if !isSubset(d[i], e[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if dn, ok := toFloat(desired); ok {
+		en, ok := toFloat(existing)
+		return ok && dn == en
+	}
+
+	return desired == existing
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+
+	return 0, false
+}
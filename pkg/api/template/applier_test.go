@@ -0,0 +1,425 @@
+package template
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type fakeClient struct {
+	objects   map[string]*unstructured.Unstructured
+	updates   int
+	failNames map[string]bool
+	// defaults mimics the server, filling in the stored objects
+	defaults func(u *unstructured.Unstructured)
+}
+
+func newFakeClient(objects ...*unstructured.Unstructured) *fakeClient {
+	c := &fakeClient{
+		objects:   make(map[string]*unstructured.Unstructured),
+		failNames: make(map[string]bool),
+	}
+	for _, obj := range objects {
+		c.objects[fakeKey(obj.GetKind(), obj.GetNamespace(), obj.GetName())] = obj
+	}
+
+	return c
+}
+
+func fakeKey(kind, ns, name string) string {
+	return kind + "/" + ns + "/" + name
+}
+
+func (c *fakeClient) Get(ctx context.Context, key types.NamespacedName, obj runtime.Object) error {
+	u := obj.(*unstructured.Unstructured)
+	existing, ok := c.objects[fakeKey(u.GetKind(), key.Namespace, key.Name)]
+	if !ok {
+		return apierrors.NewNotFound(schema.GroupResource{Resource: u.GetKind()}, key.Name)
+	}
+
+	u.Object = existing.DeepCopy().Object
+	return nil
+}
+
+func (c *fakeClient) Create(ctx context.Context, obj runtime.Object) error {
+	u := obj.(*unstructured.Unstructured)
+	if c.failNames[u.GetName()] {
+		return errors.New("create failed")
+	}
+
+	c.store(u)
+	return nil
+}
+
+func (c *fakeClient) store(u *unstructured.Unstructured) {
+	stored := u.DeepCopy()
+	if c.defaults != nil {
+		c.defaults(stored)
+	}
+
+	c.objects[fakeKey(u.GetKind(), u.GetNamespace(), u.GetName())] = stored
+}
+
+func (c *fakeClient) Update(ctx context.Context, obj runtime.Object) error {
+	u := obj.(*unstructured.Unstructured)
+	c.store(u)
+	c.updates++
+	return nil
+}
+
+// serverDefaults fills in the status and the defaulted fields like an api
+// server does.
+func serverDefaults(u *unstructured.Unstructured) {
+	u.SetResourceVersion("1")
+	u.SetUID("uid")
+
+	switch u.GetKind() {
+	case "DeploymentConfig":
+		unstructured.SetNestedField(u.Object, map[string]interface{}{"latestVersion": int64(1), "replicas": int64(1)}, "status")
+	case "Service":
+		unstructured.SetNestedField(u.Object, "172.30.0.10", "spec", "clusterIP")
+		ports, _, _ := unstructured.NestedSlice(u.Object, "spec", "ports")
+		for _, item := range ports {
+			port := item.(map[string]interface{})
+			if isZeroValue(port["targetPort"]) {
+				port["targetPort"] = port["port"]
+			}
+		}
+		unstructured.SetNestedSlice(u.Object, ports, "spec", "ports")
+		unstructured.SetNestedField(u.Object, map[string]interface{}{"loadBalancer": map[string]interface{}{}}, "status")
+	case "Route":
+		unstructured.SetNestedField(u.Object, "params-app.apps.example.com", "spec", "host")
+		unstructured.SetNestedField(u.Object, []interface{}{map[string]interface{}{"host": "params-app.apps.example.com"}}, "status", "ingress")
+	}
+}
+
+func loadObjects(t *testing.T, path string) []runtime.Object {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to open mock file: %v", err)
+	}
+
+	tmpl, err := New(nil, b)
+	if err != nil {
+		t.Fatalf("Failed to create template: %v", err)
+	}
+
+	err = tmpl.ProcessLocal(map[string]string{"NAMESPACE": "test"})
+	if err != nil {
+		t.Fatalf("Failed to process template: %v", err)
+	}
+
+	return tmpl.GetObjects(NoFilterFn)
+}
+
+func TestApplier_Apply(t *testing.T) {
+	existingService := &unstructured.Unstructured{}
+	existingService.SetAPIVersion("v1")
+	existingService.SetKind("Service")
+	existingService.SetNamespace("test")
+	existingService.SetName("params-app")
+	existingService.SetResourceVersion("10")
+	unstructured.SetNestedField(existingService.Object, "172.30.0.10", "spec", "clusterIP")
+
+	existingRoute := &unstructured.Unstructured{}
+	existingRoute.SetAPIVersion("route.openshift.io/v1")
+	existingRoute.SetKind("Route")
+	existingRoute.SetNamespace("test")
+	existingRoute.SetName("params-app")
+	unstructured.SetNestedField(existingRoute.Object, "params-app.apps.example.com", "spec", "host")
+
+	cases := []struct {
+		Name        string
+		Client      func() *fakeClient
+		Validate    func(client *fakeClient, report ApplyReport)
+		ExpectError bool
+	}{
+		{
+			Name: "Should create missing objects",
+			Client: func() *fakeClient {
+				return newFakeClient()
+			},
+			Validate: func(client *fakeClient, report ApplyReport) {
+				for _, result := range report {
+					if result.Action != ApplyCreated || result.Namespace != "test" {
+						t.Fatalf("Object should be created in test namespace: %v", result)
+					}
+				}
+
+				if len(client.objects) != 3 {
+					t.Fatalf("Failed to create objects: %v", client.objects)
+				}
+			},
+			ExpectError: false,
+		},
+		{
+			Name: "Should update existing objects keeping server fields",
+			Client: func() *fakeClient {
+				return newFakeClient(existingService.DeepCopy(), existingRoute.DeepCopy())
+			},
+			Validate: func(client *fakeClient, report ApplyReport) {
+				if report[0].Action != ApplyCreated || report[1].Action != ApplyUpdated || report[2].Action != ApplyUpdated {
+					t.Fatalf("Unexpected report: %v", report)
+				}
+
+				svc := client.objects[fakeKey("Service", "test", "params-app")]
+				if ip, _, _ := unstructured.NestedString(svc.Object, "spec", "clusterIP"); ip != "172.30.0.10" {
+					t.Fatalf("Service clusterIP should be kept: %s", ip)
+				}
+				if svc.GetResourceVersion() != "10" {
+					t.Fatalf("Update should send the existing resource version: %s", svc.GetResourceVersion())
+				}
+
+				route := client.objects[fakeKey("Route", "test", "params-app")]
+				if host, _, _ := unstructured.NestedString(route.Object, "spec", "host"); host != "params-app.apps.example.com" {
+					t.Fatalf("Route host should be kept: %s", host)
+				}
+			},
+			ExpectError: false,
+		},
+		{
+			Name: "Should report unchanged objects",
+			Client: func() *fakeClient {
+				client := newFakeClient()
+				NewApplier(client, "test").Apply(context.TODO(), loadObjects(t, "_testdata/template-params.json"))
+				return client
+			},
+			Validate: func(client *fakeClient, report ApplyReport) {
+				for _, result := range report {
+					if result.Action != ApplyUnchanged {
+						t.Fatalf("Object should be unchanged: %v", result)
+					}
+				}
+
+				if client.updates != 0 {
+					t.Fatalf("Unchanged objects should not be updated: %d", client.updates)
+				}
+			},
+			ExpectError: false,
+		},
+		{
+			Name: "Should report unchanged objects defaulted by the server",
+			Client: func() *fakeClient {
+				client := newFakeClient()
+				client.defaults = serverDefaults
+				NewApplier(client, "test").Apply(context.TODO(), loadObjects(t, "_testdata/template-params.json"))
+				return client
+			},
+			Validate: func(client *fakeClient, report ApplyReport) {
+				for _, result := range report {
+					if result.Action != ApplyUnchanged {
+						t.Fatalf("Object should be unchanged: %v", result)
+					}
+				}
+
+				if client.updates != 0 {
+					t.Fatalf("Unchanged objects should not be updated: %d", client.updates)
+				}
+			},
+			ExpectError: false,
+		},
+		{
+			Name: "Should report failed objects",
+			Client: func() *fakeClient {
+				client := newFakeClient()
+				client.failNames["params-app"] = true
+				return client
+			},
+			Validate: func(client *fakeClient, report ApplyReport) {
+				for _, result := range report {
+					if result.Action != ApplyFailed || result.Err == nil {
+						t.Fatalf("Object should fail: %v", result)
+					}
+				}
+			},
+			ExpectError: true,
+		},
+	}
+
+	for _, tc := range cases {
+		client := tc.Client()
+		report, err := NewApplier(client, "test").Apply(context.TODO(), loadObjects(t, "_testdata/template-params.json"))
+
+		if tc.ExpectError && err == nil {
+			t.Fatalf("\"%s\" expected an error but got none", tc.Name)
+		}
+
+		if !tc.ExpectError && err != nil {
+			t.Fatalf("\"%s\" did not expect error but got %s", tc.Name, err)
+		}
+
+		if len(report) != 3 {
+			t.Fatalf("\"%s\" expected a result per object: %v", tc.Name, report)
+		}
+
+		tc.Validate(client, report)
+	}
+}
+
+func TestMergeExisting(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Desired  string
+		Existing string
+		Validate func(merged *unstructured.Unstructured)
+	}{
+		{
+			Name:     "Should keep finalizers and owner references of other controllers",
+			Desired:  `{"apiVersion": "v1", "kind": "PersistentVolumeClaim", "metadata": {"name": "data", "finalizers": ["example.com/backup"]}}`,
+			Existing: `{"apiVersion": "v1", "kind": "PersistentVolumeClaim", "metadata": {"name": "data", "finalizers": ["kubernetes.io/pvc-protection"], "ownerReferences": [{"apiVersion": "v1", "kind": "ConfigMap", "name": "owner", "uid": "1"}]}}`,
+			Validate: func(merged *unstructured.Unstructured) {
+				finalizers := merged.GetFinalizers()
+				if len(finalizers) != 2 || finalizers[0] != "kubernetes.io/pvc-protection" || finalizers[1] != "example.com/backup" {
+					t.Fatalf("Unexpected finalizers: %v", finalizers)
+				}
+
+				refs := merged.GetOwnerReferences()
+				if len(refs) != 1 || refs[0].UID != "1" {
+					t.Fatalf("Unexpected owner references: %v", refs)
+				}
+			},
+		},
+		{
+			Name:     "Should replace the existing controller reference",
+			Desired:  `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "config", "ownerReferences": [{"apiVersion": "v1", "kind": "ConfigMap", "name": "new", "uid": "2", "controller": true}]}}`,
+			Existing: `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "config", "ownerReferences": [{"apiVersion": "v1", "kind": "ConfigMap", "name": "old", "uid": "1", "controller": true}, {"apiVersion": "v1", "kind": "ConfigMap", "name": "other", "uid": "3"}]}}`,
+			Validate: func(merged *unstructured.Unstructured) {
+				refs := merged.GetOwnerReferences()
+				if len(refs) != 2 || refs[0].UID != "3" || refs[1].UID != "2" {
+					t.Fatalf("Unexpected owner references: %v", refs)
+				}
+			},
+		},
+		{
+			Name:     "Should keep allocated node ports",
+			Desired:  `{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "web"}, "spec": {"type": "NodePort", "ports": [{"name": "http", "port": 80}, {"port": 443}, {"name": "admin", "port": 9000, "nodePort": 30900}]}}`,
+			Existing: `{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "web"}, "spec": {"type": "NodePort", "ports": [{"port": 443, "protocol": "TCP", "nodePort": 30443}, {"name": "http", "port": 80, "nodePort": 30080}, {"name": "admin", "port": 9000, "nodePort": 31000}]}}`,
+			Validate: func(merged *unstructured.Unstructured) {
+				ports, _, _ := unstructured.NestedSlice(merged.Object, "spec", "ports")
+				expected := []int64{30080, 30443, 30900}
+				for i, port := range ports {
+					if port.(map[string]interface{})["nodePort"] != expected[i] {
+						t.Fatalf("Unexpected ports: %v", ports)
+					}
+				}
+			},
+		},
+		{
+			Name:     "Should not keep node ports of cluster ip services",
+			Desired:  `{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "web"}, "spec": {"type": "ClusterIP", "ports": [{"name": "http", "port": 80}]}}`,
+			Existing: `{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "web"}, "spec": {"type": "NodePort", "ports": [{"name": "http", "port": 80, "nodePort": 30080}]}}`,
+			Validate: func(merged *unstructured.Unstructured) {
+				ports, _, _ := unstructured.NestedSlice(merged.Object, "spec", "ports")
+				if _, ok := ports[0].(map[string]interface{})["nodePort"]; ok {
+					t.Fatalf("Unexpected ports: %v", ports)
+				}
+			},
+		},
+		{
+			Name: "Should keep the images of image change triggers",
+			Desired: `{"apiVersion": "apps.openshift.io/v1", "kind": "DeploymentConfig", "metadata": {"name": "web"}, "spec": {
+				"triggers": [{"type": "ImageChange", "imageChangeParams": {"automatic": true, "containerNames": ["web"], "from": {"kind": "ImageStreamTag", "name": "web:latest"}}}],
+				"template": {"spec": {"containers": [{"name": "web", "image": " "}, {"name": "proxy", "image": "proxy:2"}]}}}}`,
+			Existing: `{"apiVersion": "apps.openshift.io/v1", "kind": "DeploymentConfig", "metadata": {"name": "web"}, "spec": {
+				"triggers": [{"type": "ImageChange", "imageChangeParams": {"automatic": true, "containerNames": ["web"], "from": {"kind": "ImageStreamTag", "name": "web:latest"}, "lastTriggeredImage": "registry/web@sha256:1234"}}],
+				"template": {"spec": {"containers": [{"name": "web", "image": "registry/web@sha256:1234"}, {"name": "proxy", "image": "proxy:1"}]}}}}`,
+			Validate: func(merged *unstructured.Unstructured) {
+				containers, _, _ := unstructured.NestedSlice(merged.Object, "spec", "template", "spec", "containers")
+				if containers[0].(map[string]interface{})["image"] != "registry/web@sha256:1234" {
+					t.Fatalf("Triggered image should be kept: %v", containers)
+				}
+				if containers[1].(map[string]interface{})["image"] != "proxy:2" {
+					t.Fatalf("Image without trigger should be updated: %v", containers)
+				}
+
+				triggers, _, _ := unstructured.NestedSlice(merged.Object, "spec", "triggers")
+				image, _, _ := unstructured.NestedString(triggers[0].(map[string]interface{}), "imageChangeParams", "lastTriggeredImage")
+				if image != "registry/web@sha256:1234" {
+					t.Fatalf("Last triggered image should be kept: %v", triggers)
+				}
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		desired := &unstructured.Unstructured{}
+		err := desired.UnmarshalJSON([]byte(tc.Desired))
+		if err != nil {
+			t.Fatalf("\"%s\" failed to decode desired object: %v", tc.Name, err)
+		}
+
+		existing := &unstructured.Unstructured{}
+		err = existing.UnmarshalJSON([]byte(tc.Existing))
+		if err != nil {
+			t.Fatalf("\"%s\" failed to decode existing object: %v", tc.Name, err)
+		}
+
+		merged, err := mergeExisting(desired, existing)
+		if err != nil {
+			t.Fatalf("\"%s\" did not expect error but got %s", tc.Name, err)
+		}
+
+		tc.Validate(merged)
+	}
+}
+
+func TestNewDynamicClient(t *testing.T) {
+	requests := make([]string, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests = append(requests, req.Method+" "+req.URL.Path)
+
+		w.Header().Set("Content-Type", "application/json")
+		if req.Method == http.MethodGet {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(apierrors.NewNotFound(schema.GroupResource{Resource: "routes"}, "params-app").Status())
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		body, _ := ioutil.ReadAll(req.Body)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	client, err := dynamic.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create dynamic client: %v", err)
+	}
+
+	gvk := schema.GroupVersionKind{Group: "route.openshift.io", Version: "v1", Kind: "Route"}
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{gvk.GroupVersion()})
+	mapper.Add(gvk, meta.RESTScopeNamespace)
+
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(gvk)
+	route.SetName("params-app")
+
+	report, err := NewApplier(NewDynamicClient(client, mapper), "test").Apply(context.TODO(), []runtime.Object{route})
+	if err != nil {
+		t.Fatalf("Test failed: %v", err)
+	}
+
+	if report[0].Action != ApplyCreated {
+		t.Fatalf("Route should be created: %v", report)
+	}
+
+	expected := []string{
+		"GET /apis/route.openshift.io/v1/namespaces/test/routes/params-app",
+		"POST /apis/route.openshift.io/v1/namespaces/test/routes",
+	}
+	if len(requests) != len(expected) || requests[0] != expected[0] || requests[1] != expected[1] {
+		t.Fatalf("Unexpected requests: %v", requests)
+	}
+}
@@ -0,0 +1,130 @@
+package template
+
+import (
+	"context"
+	"fmt"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+type dynamicClient struct {
+	client dynamic.Interface
+	mapper meta.RESTMapper
+}
+
+// DynamicClient adapts a dynamic client to the Client and Deleter interfaces.
+type DynamicClient interface {
+	Client
+	Deleter
+}
+
+// NewDynamicClient adapts a dynamic client to the Client and Deleter
+// interfaces. The mapper resolves the resource and scope of each object kind.
+func NewDynamicClient(client dynamic.Interface, mapper meta.RESTMapper) DynamicClient {
+	return &dynamicClient{
+		client: client,
+		mapper: mapper,
+	}
+}
+
+func (c *dynamicClient) resource(obj runtime.Object, ns string) (dynamic.ResourceInterface, error) {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+
+	mapping, err := c.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		return c.client.Resource(mapping.Resource).Namespace(ns), nil
+	}
+
+	return c.client.Resource(mapping.Resource), nil
+}
+
+func asUnstructured(obj runtime.Object) (*unstructured.Unstructured, error) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("dynamic client only supports unstructured objects, got %T", obj)
+	}
+
+	return u, nil
+}
+
+func (c *dynamicClient) Get(ctx context.Context, key types.NamespacedName, obj runtime.Object) error {
+	u, err := asUnstructured(obj)
+	if err != nil {
+		return err
+	}
+
+	resource, err := c.resource(u, key.Namespace)
+	if err != nil {
+		return err
+	}
+
+	res, err := resource.Get(key.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	u.Object = res.Object
+	return nil
+}
+
+func (c *dynamicClient) Create(ctx context.Context, obj runtime.Object) error {
+	u, err := asUnstructured(obj)
+	if err != nil {
+		return err
+	}
+
+	resource, err := c.resource(u, u.GetNamespace())
+	if err != nil {
+		return err
+	}
+
+	res, err := resource.Create(u)
+	if err != nil {
+		return err
+	}
+
+	u.Object = res.Object
+	return nil
+}
+
+func (c *dynamicClient) Update(ctx context.Context, obj runtime.Object) error {
+	u, err := asUnstructured(obj)
+	if err != nil {
+		return err
+	}
+
+	resource, err := c.resource(u, u.GetNamespace())
+	if err != nil {
+		return err
+	}
+
+	res, err := resource.Update(u)
+	if err != nil {
+		return err
+	}
+
+	u.Object = res.Object
+	return nil
+}
+
+func (c *dynamicClient) Delete(ctx context.Context, obj runtime.Object) error {
+	u, err := asUnstructured(obj)
+	if err != nil {
+		return err
+	}
+
+	resource, err := c.resource(u, u.GetNamespace())
+	if err != nil {
+		return err
+	}
+
+	return resource.Delete(u.GetName(), &metav1.DeleteOptions{})
+}
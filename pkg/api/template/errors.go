@@ -0,0 +1,91 @@
+package template
+
+import (
+	"fmt"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"net/http"
+)
+
+type ProcessErrorType string
+
+const (
+	ProcessErrorValidation ProcessErrorType = "Validation"
+	ProcessErrorAuth       ProcessErrorType = "Auth"
+	ProcessErrorTransport  ProcessErrorType = "Transport"
+)
+
+// ProcessError wraps the error returned by the api server or the transport,
+// telling apart failures caused by the template, by the credentials and by
+// the connection to the server.
+type ProcessError struct {
+	Type ProcessErrorType
+	Err  error
+}
+
+func newProcessError(errorType ProcessErrorType, err error) *ProcessError {
+	return &ProcessError{
+		Type: errorType,
+		Err:  err,
+	}
+}
+
+func (e *ProcessError) Error() string {
+	return fmt.Sprintf("template processing failed (%s): %v", e.Type, e.Err)
+}
+
+// Status delegates to the wrapped error when it comes from the api server,
+// so checks like errors.IsNotFound keep working on processing errors.
+func (e *ProcessError) Status() metav1.Status {
+	if status, ok := e.Err.(errors.APIStatus); ok {
+		return status.Status()
+	}
+
+	return metav1.Status{
+		Status:  metav1.StatusFailure,
+		Reason:  metav1.StatusReasonUnknown,
+		Message: e.Error(),
+	}
+}
+
+func (e *ProcessError) Unwrap() error {
+	return e.Err
+}
+
+func IsValidationError(err error) bool {
+	return isProcessErrorType(err, ProcessErrorValidation)
+}
+
+func IsAuthError(err error) bool {
+	return isProcessErrorType(err, ProcessErrorAuth)
+}
+
+func IsTransportError(err error) bool {
+	return isProcessErrorType(err, ProcessErrorTransport)
+}
+
+func isProcessErrorType(err error, errorType ProcessErrorType) bool {
+	e, ok := err.(*ProcessError)
+	return ok && e.Type == errorType
+}
+
+func classifyError(err error) *ProcessError {
+	switch {
+	case errors.IsUnauthorized(err), errors.IsForbidden(err):
+		return newProcessError(ProcessErrorAuth, err)
+	case errors.IsBadRequest(err), errors.IsInvalid(err):
+		return newProcessError(ProcessErrorValidation, err)
+	}
+
+	return newProcessError(ProcessErrorTransport, err)
+}
+
+func isTransient(err error) bool {
+	if status, ok := err.(errors.APIStatus); ok {
+		code := status.Status().Code
+		return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+	}
+
+	return utilnet.IsConnectionReset(err) || utilnet.IsProbableEOF(err)
+}
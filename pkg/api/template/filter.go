@@ -0,0 +1,162 @@
+package template
+
+import (
+	"fmt"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"strings"
+)
+
+// ByKind keeps the objects of one of the given kinds.
+func ByKind(kinds ...string) FilterFn {
+	return func(obj *runtime.Object) error {
+		kind := (*obj).GetObjectKind().GroupVersionKind().Kind
+		for _, k := range kinds {
+			if k == kind {
+				return nil
+			}
+		}
+
+		return rejected(*obj, "kind is not one of %s", strings.Join(kinds, ", "))
+	}
+}
+
+// ByGroupVersionKind keeps the objects of one of the given group, version
+// and kind.
+func ByGroupVersionKind(gvks ...schema.GroupVersionKind) FilterFn {
+	return func(obj *runtime.Object) error {
+		gvk := (*obj).GetObjectKind().GroupVersionKind()
+		names := make([]string, 0, len(gvks))
+		for _, g := range gvks {
+			if g == gvk {
+				return nil
+			}
+			names = append(names, g.String())
+		}
+
+		return rejected(*obj, "group, version and kind is not one of %s", strings.Join(names, "; "))
+	}
+}
+
+// ByName keeps the objects with one of the given names.
+func ByName(names ...string) FilterFn {
+	return func(obj *runtime.Object) error {
+		accessor, err := meta.Accessor(*obj)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			if name == accessor.GetName() {
+				return nil
+			}
+		}
+
+		return rejected(*obj, "name is not one of %s", strings.Join(names, ", "))
+	}
+}
+
+// ByLabelSelector keeps the objects whose labels match selector, written in
+// the labels.Selector syntax, e.g. "app=web,tier notin (db)".
+func ByLabelSelector(selector string) (FilterFn, error) {
+	s, err := labels.Parse(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(obj *runtime.Object) error {
+		accessor, err := meta.Accessor(*obj)
+		if err != nil {
+			return err
+		}
+
+		if s.Matches(labels.Set(accessor.GetLabels())) {
+			return nil
+		}
+
+		return rejected(*obj, "labels do not match %s", s.String())
+	}, nil
+}
+
+// ByAnnotation keeps the objects annotated with key. When values are given
+// the annotation must also have one of them.
+func ByAnnotation(key string, values ...string) FilterFn {
+	return func(obj *runtime.Object) error {
+		accessor, err := meta.Accessor(*obj)
+		if err != nil {
+			return err
+		}
+
+		value, ok := accessor.GetAnnotations()[key]
+		if !ok {
+			return rejected(*obj, "annotation %s is not set", key)
+		}
+
+		if len(values) == 0 {
+			return nil
+		}
+
+		for _, v := range values {
+			if v == value {
+				return nil
+			}
+		}
+
+		return rejected(*obj, "annotation %s=%s is not one of %s", key, value, strings.Join(values, ", "))
+	}
+}
+
+// Not keeps the objects rejected by filter.
+func Not(filter FilterFn) FilterFn {
+	return func(obj *runtime.Object) error {
+		if filter(obj) != nil {
+			return nil
+		}
+
+		return rejected(*obj, "matched a negated filter")
+	}
+}
+
+// And keeps the objects kept by every filter, reporting the first rejection.
+func And(filters ...FilterFn) FilterFn {
+	return func(obj *runtime.Object) error {
+		for _, filter := range filters {
+			err := filter(obj)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// Or keeps the objects kept by any filter, reporting every rejection when
+// none of them does.
+func Or(filters ...FilterFn) FilterFn {
+	return func(obj *runtime.Object) error {
+		errs := make([]error, 0, len(filters))
+		for _, filter := range filters {
+			err := filter(obj)
+			if err == nil {
+				return nil
+			}
+			errs = append(errs, err)
+		}
+
+		return utilerrors.NewAggregate(errs)
+	}
+}
+
+func rejected(obj runtime.Object, format string, args ...interface{}) error {
+	name := ""
+	if accessor, err := meta.Accessor(obj); err == nil {
+		name = accessor.GetName()
+	}
+
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	return fmt.Errorf("%s %s rejected: %s", kind, name, fmt.Sprintf(format, args...))
+}
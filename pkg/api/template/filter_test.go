@@ -0,0 +1,121 @@
+package template
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"strings"
+	"testing"
+)
+
+func TestFilters(t *testing.T) {
+	objects := loadObjects(t, "_testdata/template-params.json")
+	for _, obj := range objects {
+		accessor, _ := meta.Accessor(obj)
+		if obj.GetObjectKind().GroupVersionKind().Kind == "Route" {
+			accessor.SetAnnotations(map[string]string{"expose": "public"})
+		}
+	}
+
+	selector, err := ByLabelSelector("app=params-app,template")
+	if err != nil {
+		t.Fatalf("Failed to parse selector: %v", err)
+	}
+
+	cases := []struct {
+		Name   string
+		Filter FilterFn
+		Kinds  []string
+		Reason string
+	}{
+		{
+			Name:   "Should keep objects by kind",
+			Filter: ByKind("Service", "Route"),
+			Kinds:  []string{"Service", "Route"},
+			Reason: "DeploymentConfig params-app rejected: kind is not one of Service, Route",
+		},
+		{
+			Name:   "Should keep objects by group, version and kind",
+			Filter: ByGroupVersionKind(schema.GroupVersionKind{Group: "route.openshift.io", Version: "v1", Kind: "Route"}),
+			Kinds:  []string{"Route"},
+			Reason: "kind is not one of route.openshift.io/v1, Kind=Route",
+		},
+		{
+			Name:   "Should keep objects by name",
+			Filter: ByName("params-app"),
+			Kinds:  []string{"DeploymentConfig", "Service", "Route"},
+		},
+		{
+			Name:   "Should keep objects by label selector",
+			Filter: selector,
+			Kinds:  []string{"DeploymentConfig"},
+			Reason: "labels do not match app=params-app,template",
+		},
+		{
+			Name:   "Should keep objects by annotation",
+			Filter: ByAnnotation("expose", "public", "internal"),
+			Kinds:  []string{"Route"},
+			Reason: "annotation expose is not set",
+		},
+		{
+			Name:   "Should keep objects rejected by a negated filter",
+			Filter: Not(ByKind("Route")),
+			Kinds:  []string{"DeploymentConfig", "Service"},
+			Reason: "Route params-app rejected: matched a negated filter",
+		},
+		{
+			Name:   "Should keep objects kept by every filter",
+			Filter: And(ByName("params-app"), ByKind("Service")),
+			Kinds:  []string{"Service"},
+			Reason: "kind is not one of Service",
+		},
+		{
+			Name:   "Should keep objects kept by any filter",
+			Filter: Or(ByKind("Service"), ByAnnotation("expose")),
+			Kinds:  []string{"Service", "Route"},
+			Reason: "[DeploymentConfig params-app rejected: kind is not one of Service, DeploymentConfig params-app rejected: annotation expose is not set]",
+		},
+	}
+
+	for _, tc := range cases {
+		kinds := make([]string, 0)
+		reasons := make([]string, 0)
+		for i := range objects {
+			obj := objects[i]
+			err := tc.Filter(&obj)
+			if err != nil {
+				reasons = append(reasons, err.Error())
+				continue
+			}
+			kinds = append(kinds, obj.GetObjectKind().GroupVersionKind().Kind)
+		}
+
+		if strings.Join(kinds, ",") != strings.Join(tc.Kinds, ",") {
+			t.Fatalf("\"%s\" expected %v but got %v", tc.Name, tc.Kinds, kinds)
+		}
+
+		if tc.Reason != "" && !strings.Contains(strings.Join(reasons, "\n"), tc.Reason) {
+			t.Fatalf("\"%s\" expected rejection reason %q but got %v", tc.Name, tc.Reason, reasons)
+		}
+	}
+}
+
+func TestByLabelSelector(t *testing.T) {
+	_, err := ByLabelSelector("app in (")
+	if err == nil {
+		t.Fatalf("Invalid selector should fail")
+	}
+}
+
+func TestTmpl_GetObjects_Filter(t *testing.T) {
+	tmpl := &Tmpl{Objects: loadObjects(t, "_testdata/template-params.json")}
+
+	objects := tmpl.GetObjects(Not(ByKind("Route")))
+	if len(objects) != 2 {
+		t.Fatalf("Filtered objects should be returned: %v", objects)
+	}
+
+	obj := objects[0]
+	if ByKind("DeploymentConfig")(&obj) != nil {
+		t.Fatalf("Objects should keep the template order: %v", obj)
+	}
+}
@@ -0,0 +1,220 @@
+package template
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ExpressionGeneratorName = "expression"
+
+	alphabet = "abcdefghijklmnopqrstuvwxyz"
+	numerals = "0123456789"
+	symbols  = "~!@#$%^&*()-_+={}[]\\|<,>.?/\"';:`"
+
+	maxGeneratedLength = 255
+)
+
+var (
+	generatorExp  = regexp.MustCompile(`\[([a-zA-Z0-9\-\\]+)\]\{(\w+)\}`)
+	classRangeExp = regexp.MustCompile(`\\[wdaA]|[a-zA-Z0-9]\-[a-zA-Z0-9]|[a-zA-Z0-9]`)
+)
+
+// Generator produces a parameter value from the parameter "from" field.
+type Generator interface {
+	GenerateValue(from string) (string, error)
+}
+
+// ExpressionGenerator implements the openshift "expression" generator.
+// Expressions are literal text mixed with character classes followed by a
+// repetition count, e.g. "[a-zA-Z0-9]{16}" or "test[\d]{4}". Inside a class
+// the ranges a-z, A-Z, 0-9 and the shortcuts \w, \d, \a (letters) and
+// \A (symbols) are supported.
+type ExpressionGenerator struct {
+	rand *rand.Rand
+}
+
+// NewExpressionGenerator creates a generator backed by the given random
+// source. The source is not safe for concurrent use, so it should not be
+// shared between goroutines.
+func NewExpressionGenerator(source *rand.Rand) *ExpressionGenerator {
+	return &ExpressionGenerator{
+		rand: source,
+	}
+}
+
+func DefaultGenerators() map[string]Generator {
+	return map[string]Generator{
+		ExpressionGeneratorName: NewExpressionGenerator(rand.New(rand.NewSource(time.Now().UnixNano()))),
+	}
+}
+
+func (g *ExpressionGenerator) GenerateValue(from string) (string, error) {
+	var err error
+
+	result := generatorExp.ReplaceAllStringFunc(from, func(expression string) string {
+		if err != nil {
+			return expression
+		}
+
+		var value string
+		value, err = g.generate(expression)
+		return value
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	return result, nil
+}
+
+// ExpressionPattern converts a generator expression into an anchored regular
+// expression matching every value the generator can produce.
+func ExpressionPattern(from string) (string, error) {
+	var pattern string
+	last := 0
+
+	for _, match := range generatorExp.FindAllStringSubmatchIndex(from, -1) {
+		charset, err := expressionCharset(from[match[2]:match[3]])
+		if err != nil {
+			return "", err
+		}
+
+		length, err := expressionLength(from[match[0]:match[1]], from[match[4]:match[5]])
+		if err != nil {
+			return "", err
+		}
+
+		pattern += regexp.QuoteMeta(from[last:match[0]])
+		pattern += "[" + escapeCharset(charset) + "]{" + strconv.Itoa(length) + "}"
+		last = match[1]
+	}
+	pattern += regexp.QuoteMeta(from[last:])
+
+	return "^" + pattern + "$", nil
+}
+
+func escapeCharset(charset string) string {
+	var escaped string
+
+	for i := 0; i < len(charset); i++ {
+		c := charset[i]
+		if c == '-' {
+			escaped += "\\-"
+			continue
+		}
+
+		if !isAlphanumeric(c) {
+			escaped += regexp.QuoteMeta(string(c))
+			continue
+		}
+
+		j := i
+		for j+1 < len(charset) && charset[j+1] == charset[j]+1 && charType(charset[j+1]) == charType(c) {
+			j++
+		}
+
+		if j-i >= 2 {
+			escaped += string(c) + "-" + string(charset[j])
+			i = j
+			continue
+		}
+		escaped += string(c)
+	}
+
+	return escaped
+}
+
+func isAlphanumeric(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func expressionLength(expression, count string) (int, error) {
+	length, err := strconv.Atoi(count)
+	if err != nil || length < 1 || length > maxGeneratedLength {
+		return 0, fmt.Errorf("range must be within [1-%d] characters: %s", maxGeneratedLength, expression)
+	}
+
+	return length, nil
+}
+
+func (g *ExpressionGenerator) generate(expression string) (string, error) {
+	match := generatorExp.FindStringSubmatch(expression)
+
+	charset, err := expressionCharset(match[1])
+	if err != nil {
+		return "", err
+	}
+
+	length, err := expressionLength(expression, match[2])
+	if err != nil {
+		return "", err
+	}
+
+	result := make([]byte, length)
+	for i := range result {
+		result[i] = charset[g.rand.Intn(len(charset))]
+	}
+
+	return string(result), nil
+}
+
+func expressionCharset(class string) (string, error) {
+	if classRangeExp.ReplaceAllString(class, "") != "" {
+		return "", fmt.Errorf("malformed expression syntax: [%s]", class)
+	}
+
+	var charset string
+	for _, item := range classRangeExp.FindAllString(class, -1) {
+		switch item {
+		case `\w`:
+			charset += alphabet + strings.ToUpper(alphabet) + numerals + "_"
+		case `\d`:
+			charset += numerals
+		case `\a`:
+			charset += alphabet + strings.ToUpper(alphabet)
+		case `\A`:
+			charset += symbols
+		default:
+			from, to := item[0], item[len(item)-1]
+			if from > to || charType(from) != charType(to) {
+				return "", fmt.Errorf("invalid range specified: %s", item)
+			}
+			for c := from; c <= to; c++ {
+				charset += string(c)
+			}
+		}
+	}
+
+	return uniqueChars(charset), nil
+}
+
+func charType(c byte) int {
+	switch {
+	case c >= 'a' && c <= 'z':
+		return 0
+	case c >= 'A' && c <= 'Z':
+		return 1
+	default:
+		return 2
+	}
+}
+
+func uniqueChars(s string) string {
+	seen := make(map[rune]bool)
+	var result []rune
+
+	for _, c := range s {
+		if !seen[c] {
+			seen[c] = true
+			result = append(result, c)
+		}
+	}
+
+	return string(result)
+}
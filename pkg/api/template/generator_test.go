@@ -0,0 +1,184 @@
+package template
+
+import (
+	"math/rand"
+	"regexp"
+	"testing"
+)
+
+func TestExpressionGenerator_GenerateValue(t *testing.T) {
+	cases := []struct {
+		Name        string
+		From        string
+		Pattern     string
+		ExpectError bool
+	}{
+		{
+			Name:        "Should generate alphanumeric value",
+			From:        "[a-zA-Z0-9]{16}",
+			Pattern:     "^[a-zA-Z0-9]{16}$",
+			ExpectError: false,
+		},
+		{
+			Name:        "Should keep literal text",
+			From:        "test[0-9]{1}x",
+			Pattern:     "^test[0-9]x$",
+			ExpectError: false,
+		},
+		{
+			Name:        "Should generate hex value",
+			From:        "0x[A-F0-9]{4}",
+			Pattern:     "^0x[A-F0-9]{4}$",
+			ExpectError: false,
+		},
+		{
+			Name:        "Should generate multiple expressions",
+			From:        "[a-z]{2}-[0-9]{3}",
+			Pattern:     "^[a-z]{2}-[0-9]{3}$",
+			ExpectError: false,
+		},
+		{
+			Name:        "Should support word class",
+			From:        "[\\w]{32}",
+			Pattern:     "^[a-zA-Z0-9_]{32}$",
+			ExpectError: false,
+		},
+		{
+			Name:        "Should support digit and letter classes",
+			From:        "[\\d\\a]{32}",
+			Pattern:     "^[a-zA-Z0-9]{32}$",
+			ExpectError: false,
+		},
+		{
+			Name:        "Should support symbol class",
+			From:        "[\\A]{8}",
+			Pattern:     "^[^a-zA-Z0-9]{8}$",
+			ExpectError: false,
+		},
+		{
+			Name:        "Should fail on inverted range",
+			From:        "[z-a]{8}",
+			ExpectError: true,
+		},
+		{
+			Name:        "Should fail on mixed range",
+			From:        "[a-Z]{8}",
+			ExpectError: true,
+		},
+		{
+			Name:        "Should fail on zero length",
+			From:        "[a-z]{0}",
+			ExpectError: true,
+		},
+		{
+			Name:        "Should fail on too long value",
+			From:        "[a-z]{256}",
+			ExpectError: true,
+		},
+		{
+			Name:        "Should fail on malformed class",
+			From:        "[a-]{8}",
+			ExpectError: true,
+		},
+	}
+
+	for _, tc := range cases {
+		generator := NewExpressionGenerator(rand.New(rand.NewSource(1)))
+		value, err := generator.GenerateValue(tc.From)
+
+		if tc.ExpectError && err == nil {
+			t.Fatalf("\"%s\" expected an error but got none", tc.Name)
+		}
+
+		if !tc.ExpectError && err != nil {
+			t.Fatalf("\"%s\" did not expect error but got %s", tc.Name, err)
+		}
+
+		if !tc.ExpectError && !regexp.MustCompile(tc.Pattern).MatchString(value) {
+			t.Fatalf("\"%s\" value %s does not match %s", tc.Name, value, tc.Pattern)
+		}
+
+		if tc.ExpectError {
+			continue
+		}
+
+		pattern, err := ExpressionPattern(tc.From)
+		if err != nil {
+			t.Fatalf("\"%s\" failed to build pattern: %v", tc.Name, err)
+		}
+
+		if !regexp.MustCompile(pattern).MatchString(value) {
+			t.Fatalf("\"%s\" value %s does not match expression pattern %s", tc.Name, value, pattern)
+		}
+	}
+}
+
+func TestExpressionGenerator_Seed(t *testing.T) {
+	first, err := NewExpressionGenerator(rand.New(rand.NewSource(42))).GenerateValue("[a-zA-Z0-9]{16}")
+	if err != nil {
+		t.Fatalf("Test failed: %v", err)
+	}
+
+	second, err := NewExpressionGenerator(rand.New(rand.NewSource(42))).GenerateValue("[a-zA-Z0-9]{16}")
+	if err != nil {
+		t.Fatalf("Test failed: %v", err)
+	}
+
+	if first != second {
+		t.Fatalf("Values generated with the same seed differ: %s != %s", first, second)
+	}
+}
+
+func TestExpressionPattern(t *testing.T) {
+	cases := []struct {
+		Name        string
+		From        string
+		Pattern     string
+		ExpectError bool
+	}{
+		{
+			Name:        "Should convert alphanumeric expression",
+			From:        "[a-zA-Z0-9]{16}",
+			Pattern:     "^[a-zA-Z0-9]{16}$",
+			ExpectError: false,
+		},
+		{
+			Name:        "Should quote literal text",
+			From:        "0x.[A-F0-9]{4}",
+			Pattern:     "^0x\\.[A-F0-9]{4}$",
+			ExpectError: false,
+		},
+		{
+			Name:        "Should expand word class",
+			From:        "[\\w]{8}",
+			Pattern:     "^[a-zA-Z0-9_]{8}$",
+			ExpectError: false,
+		},
+		{
+			Name:        "Should fail on malformed expression",
+			From:        "[z-a]{8}",
+			ExpectError: true,
+		},
+		{
+			Name:        "Should fail on a range over the generated length limit",
+			From:        "[a-z]{2000}",
+			ExpectError: true,
+		},
+	}
+
+	for _, tc := range cases {
+		pattern, err := ExpressionPattern(tc.From)
+
+		if tc.ExpectError && err == nil {
+			t.Fatalf("\"%s\" expected an error but got none", tc.Name)
+		}
+
+		if !tc.ExpectError && err != nil {
+			t.Fatalf("\"%s\" did not expect error but got %s", tc.Name, err)
+		}
+
+		if pattern != tc.Pattern {
+			t.Fatalf("\"%s\" expected pattern %s but got %s", tc.Name, tc.Pattern, pattern)
+		}
+	}
+}
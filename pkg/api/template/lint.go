@@ -0,0 +1,202 @@
+package template
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/integr8ly/operator-sdk-openshift-utils/pkg/api/kubernetes"
+	"regexp"
+	"sort"
+)
+
+type LintIssueType string
+
+const (
+	LintUnusedParameter     LintIssueType = "UnusedParameter"
+	LintUndeclaredParameter LintIssueType = "UndeclaredParameter"
+	LintInvalidLiteral      LintIssueType = "InvalidLiteral"
+	LintMissingName         LintIssueType = "MissingName"
+	LintDuplicateObject     LintIssueType = "DuplicateObject"
+	LintHardcodedNamespace  LintIssueType = "HardcodedNamespace"
+	LintUnparseableObject   LintIssueType = "UnparseableObject"
+)
+
+var (
+	nonStringReferenceExp = regexp.MustCompile(`\$\{\{([a-zA-Z0-9\_]+)\}\}`)
+)
+
+// LintIssue is a problem found in a template, Path being the JSON path of
+// the offending field, e.g. $.objects[0].metadata.namespace.
+type LintIssue struct {
+	Type    LintIssueType
+	Path    string
+	Message string
+}
+
+func (i *LintIssue) String() string {
+	return fmt.Sprintf("%s: %s", i.Path, i.Message)
+}
+
+type LintIssues []*LintIssue
+
+type linter struct {
+	tmpl       *Tmpl
+	declared   map[string]int
+	referenced map[string]bool
+	issues     LintIssues
+}
+
+// Lint reports the authoring mistakes found in the template source, in
+// document order. It does not need a cluster and does not modify the
+// template.
+func Lint(tmpl *Tmpl) LintIssues {
+	l := &linter{
+		tmpl:       tmpl,
+		declared:   make(map[string]int),
+		referenced: make(map[string]bool),
+		issues:     make(LintIssues, 0),
+	}
+
+	for i, param := range tmpl.Source.Parameters {
+		l.declared[param.Name] = i
+	}
+
+	l.checkStrings("$.message", tmpl.Source.Message)
+	for _, key := range sortedKeys(tmpl.Source.ObjectLabels) {
+		l.checkStrings(kubernetes.JSONPathKey("$.labels", key), tmpl.Source.ObjectLabels[key])
+	}
+
+	objects := make(map[string]string)
+	for i, raw := range tmpl.Source.Objects {
+		path := fmt.Sprintf("$.objects[%d]", i)
+
+		data := raw.Raw
+		if data == nil && raw.Object != nil {
+			data, _ = json.Marshal(raw.Object)
+		}
+
+		obj, err := decodeRaw(data)
+		if err != nil {
+			l.add(LintUnparseableObject, path, "object is not valid json: %v", err)
+			continue
+		}
+
+		if obj["apiVersion"] == nil || obj["kind"] == nil {
+			l.add(LintUnparseableObject, path, "object has no apiVersion or kind")
+		} else {
+			l.checkObject(path, obj, objects)
+		}
+
+		l.walk(path, obj)
+	}
+
+	for _, param := range tmpl.Source.Parameters {
+		if !l.referenced[param.Name] {
+			l.add(LintUnusedParameter, fmt.Sprintf("$.parameters[%d]", l.declared[param.Name]), "parameter %s is never referenced", param.Name)
+		}
+	}
+
+	return l.issues
+}
+
+func (l *linter) add(issueType LintIssueType, path, format string, args ...interface{}) {
+	l.issues = append(l.issues, &LintIssue{
+		Type:    issueType,
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (l *linter) walk(path string, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			itemPath := kubernetes.JSONPathKey(path, key)
+			l.checkStrings(itemPath, key)
+			l.walk(itemPath, v[key])
+		}
+	case []interface{}:
+		for i, item := range v {
+			l.walk(fmt.Sprintf("%s[%d]", path, i), item)
+		}
+	case string:
+		l.checkStrings(path, v)
+	}
+}
+
+func (l *linter) checkStrings(path, value string) {
+	for _, match := range parameterExp.FindAllStringSubmatch(value, -1) {
+		l.reference(path, match[1])
+	}
+
+	for _, match := range nonStringReferenceExp.FindAllStringSubmatch(value, -1) {
+		if !l.reference(path, match[1]) {
+			continue
+		}
+
+		if match[0] != value {
+			l.add(LintInvalidLiteral, path, "%s is only replaced when it is the whole value", match[0])
+			continue
+		}
+
+		param := l.tmpl.Source.Parameters[l.declared[match[1]]]
+		if param.Value == "" || param.Generate != "" {
+			continue
+		}
+
+		var literal interface{}
+		if json.Unmarshal([]byte(param.Value), &literal) != nil {
+			l.add(LintInvalidLiteral, path, "value %q of parameter %s is not a json literal", param.Value, param.Name)
+		}
+	}
+}
+
+func (l *linter) reference(path, name string) bool {
+	if _, ok := l.declared[name]; !ok {
+		l.add(LintUndeclaredParameter, path, "parameter %s is not declared", name)
+		return false
+	}
+
+	l.referenced[name] = true
+	return true
+}
+
+func (l *linter) checkObject(path string, obj map[string]interface{}, objects map[string]string) {
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	generateName, _ := metadata["generateName"].(string)
+	kind, _ := obj["kind"].(string)
+
+	if name == "" && generateName == "" {
+		l.add(LintMissingName, path+".metadata.name", "%s has no name", kind)
+	}
+
+	if name != "" {
+		key := kind + "/" + name
+		if first, ok := objects[key]; ok {
+			l.add(LintDuplicateObject, path, "%s %s is already defined at %s", kind, name, first)
+		} else {
+			objects[key] = path
+		}
+	}
+
+	ns, _ := metadata["namespace"].(string)
+	if ns != "" && !parameterExp.MatchString(ns) && !nonStringReferenceExp.MatchString(ns) {
+		l.add(LintHardcodedNamespace, path+".metadata.namespace", "%s %s hardcodes namespace %s", kind, name, ns)
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,75 @@
+package template
+
+import (
+	"github.com/openshift/api/template/v1"
+	"io/ioutil"
+	"k8s.io/apimachinery/pkg/runtime"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	b, err := ioutil.ReadFile("_testdata/template-lint.json")
+	if err != nil {
+		t.Fatalf("Failed to open mock file: %v", err)
+	}
+
+	tmpl, err := New(nil, b)
+	if err != nil {
+		t.Fatalf("Failed to create template: %v", err)
+	}
+
+	expected := []LintIssue{
+		{Type: LintHardcodedNamespace, Path: "$.objects[0].metadata.namespace"},
+		{Type: LintInvalidLiteral, Path: "$.objects[0].spec.replicas"},
+		{Type: LintUndeclaredParameter, Path: "$.objects[0].spec.template.spec.containers[0].image"},
+		{Type: LintMissingName, Path: "$.objects[1].metadata.name"},
+		{Type: LintInvalidLiteral, Path: "$.objects[1].metadata.annotations['app.kubernetes.io/size']"},
+		{Type: LintDuplicateObject, Path: "$.objects[3]"},
+		{Type: LintUnparseableObject, Path: "$.objects[4]"},
+		{Type: LintUnusedParameter, Path: "$.parameters[4]"},
+	}
+
+	issues := Lint(tmpl)
+	if len(issues) != len(expected) {
+		t.Fatalf("Expected %d issues but got %d: %v", len(expected), len(issues), issues)
+	}
+
+	for i, issue := range issues {
+		if issue.Type != expected[i].Type || issue.Path != expected[i].Path {
+			t.Fatalf("Expected issue %s at %s but got %v", expected[i].Type, expected[i].Path, issue)
+		}
+	}
+}
+
+func TestLint_Clean(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Template *v1.Template
+	}{
+		{
+			Name: "Should accept valid json literals",
+			Template: &v1.Template{
+				Objects: []runtime.RawExtension{
+					{Raw: []byte(`{"apiVersion": "v1", "kind": "ReplicationController", "metadata": {"name": "app"}, "spec": {"replicas": "${{REPLICAS}}"}}`)},
+				},
+				Parameters: []v1.Parameter{{Name: "REPLICAS", Value: "2"}},
+			},
+		},
+		{
+			Name: "Should accept generated names and parameterized namespaces",
+			Template: &v1.Template{
+				Objects: []runtime.RawExtension{
+					{Raw: []byte(`{"apiVersion": "v1", "kind": "Pod", "metadata": {"generateName": "job-", "namespace": "${NAMESPACE}"}}`)},
+				},
+				Parameters: []v1.Parameter{{Name: "NAMESPACE"}},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		issues := Lint(&Tmpl{Source: tc.Template})
+		if len(issues) != 0 {
+			t.Fatalf("\"%s\" did not expect issues but got %v", tc.Name, issues)
+		}
+	}
+}
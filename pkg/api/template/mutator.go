@@ -0,0 +1,277 @@
+package template
+
+import (
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"strings"
+)
+
+var (
+	podSpecPaths = map[string][]string{
+		"Pod":                   {"spec"},
+		"PodTemplate":           {"template", "spec"},
+		"ReplicationController": {"spec", "template", "spec"},
+		"ReplicaSet":            {"spec", "template", "spec"},
+		"Deployment":            {"spec", "template", "spec"},
+		"DeploymentConfig":      {"spec", "template", "spec"},
+		"DaemonSet":             {"spec", "template", "spec"},
+		"StatefulSet":           {"spec", "template", "spec"},
+		"Job":                   {"spec", "template", "spec"},
+		"CronJob":               {"spec", "jobTemplate", "spec", "template", "spec"},
+	}
+)
+
+// MutateNamespace moves every namespaced object to ns. The scope of the
+// objects is looked up in mapper.
+func MutateNamespace(ns string, mapper meta.RESTMapper) MutatorFn {
+	return func(obj runtime.Object) error {
+		namespaced, err := isNamespaced(mapper, obj)
+		if err != nil {
+			return err
+		}
+		if !namespaced {
+			return nil
+		}
+
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return err
+		}
+
+		accessor.SetNamespace(ns)
+		return nil
+	}
+}
+
+// MutateLabels adds labels to every object, overriding the existing values.
+func MutateLabels(labels map[string]string) MutatorFn {
+	return func(obj runtime.Object) error {
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return err
+		}
+
+		accessor.SetLabels(mergeMaps(accessor.GetLabels(), labels))
+		return nil
+	}
+}
+
+// MutateAnnotations adds annotations to every object, overriding the
+// existing values.
+func MutateAnnotations(annotations map[string]string) MutatorFn {
+	return func(obj runtime.Object) error {
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return err
+		}
+
+		accessor.SetAnnotations(mergeMaps(accessor.GetAnnotations(), annotations))
+		return nil
+	}
+}
+
+// MutateImagePullSecrets adds the secrets to the pod spec of every workload
+// and to every service account.
+func MutateImagePullSecrets(names ...string) MutatorFn {
+	return func(obj runtime.Object) error {
+		kind := obj.GetObjectKind().GroupVersionKind().Kind
+
+		path, ok := podSpecPaths[kind]
+		if kind == "ServiceAccount" {
+			path, ok = []string{}, true
+		}
+		if !ok {
+			return nil
+		}
+
+		return mutateUnstructured(obj, func(u map[string]interface{}) error {
+			secrets, _, err := unstructured.NestedSlice(u, append(path, "imagePullSecrets")...)
+			if err != nil {
+				return err
+			}
+
+			existing := make(map[string]bool)
+			for _, secret := range secrets {
+				if ref, ok := secret.(map[string]interface{}); ok {
+					existing[fmt.Sprint(ref["name"])] = true
+				}
+			}
+
+			for _, name := range names {
+				if !existing[name] {
+					secrets = append(secrets, map[string]interface{}{"name": name})
+					existing[name] = true
+				}
+			}
+
+			return unstructured.SetNestedSlice(u, secrets, append(path, "imagePullSecrets")...)
+		})
+	}
+}
+
+// MutateResources overrides the limits and requests set in resources on
+// every container of every workload.
+func MutateResources(resources corev1.ResourceRequirements) MutatorFn {
+	return func(obj runtime.Object) error {
+		return mutateContainers(obj, func(container map[string]interface{}) error {
+			for _, field := range []struct {
+				name string
+				list corev1.ResourceList
+			}{
+				{"limits", resources.Limits},
+				{"requests", resources.Requests},
+			} {
+				if len(field.list) == 0 {
+					continue
+				}
+
+				values, _, err := unstructured.NestedMap(container, "resources", field.name)
+				if err != nil {
+					return err
+				}
+				if values == nil {
+					values = make(map[string]interface{})
+				}
+
+				for name, quantity := range field.list {
+					values[string(name)] = quantity.String()
+				}
+
+				err = unstructured.SetNestedMap(container, values, "resources", field.name)
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+	}
+}
+
+// MutateImageRegistry replaces the from registry with to in the image of
+// every container and of every image stream tag imported from a docker
+// image. Images without a registry are considered to come from docker.io.
+func MutateImageRegistry(from, to string) MutatorFn {
+	return func(obj runtime.Object) error {
+		if obj.GetObjectKind().GroupVersionKind().Kind == "ImageStream" {
+			return mutateUnstructured(obj, func(u map[string]interface{}) error {
+				tags, _, err := unstructured.NestedSlice(u, "spec", "tags")
+				if err != nil {
+					return err
+				}
+
+				for _, tag := range tags {
+					t, ok := tag.(map[string]interface{})
+					if !ok {
+						continue
+					}
+
+					ref, ok := t["from"].(map[string]interface{})
+					if ok && ref["kind"] == "DockerImage" {
+						ref["name"] = rewriteRegistry(fmt.Sprint(ref["name"]), from, to)
+					}
+				}
+
+				return unstructured.SetNestedSlice(u, tags, "spec", "tags")
+			})
+		}
+
+		return mutateContainers(obj, func(container map[string]interface{}) error {
+			image, ok := container["image"].(string)
+			if ok {
+				container["image"] = rewriteRegistry(image, from, to)
+			}
+
+			return nil
+		})
+	}
+}
+
+func rewriteRegistry(image, from, to string) string {
+	registry, path := "docker.io", image
+
+	parts := strings.SplitN(image, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		registry, path = parts[0], parts[1]
+	}
+
+	if registry != from {
+		return image
+	}
+
+	if registry == "docker.io" && !strings.Contains(path, "/") {
+		path = "library/" + path
+	}
+
+	return to + "/" + path
+}
+
+// mutateContainers calls fn with the containers and init containers of the
+// pod spec of obj, when it has one.
+func mutateContainers(obj runtime.Object, fn func(container map[string]interface{}) error) error {
+	path, ok := podSpecPaths[obj.GetObjectKind().GroupVersionKind().Kind]
+	if !ok {
+		return nil
+	}
+
+	return mutateUnstructured(obj, func(u map[string]interface{}) error {
+		for _, field := range []string{"containers", "initContainers"} {
+			containers, found, err := unstructured.NestedSlice(u, append(path, field)...)
+			if err != nil {
+				return err
+			}
+			if !found {
+				continue
+			}
+
+			for _, container := range containers {
+				c, ok := container.(map[string]interface{})
+				if !ok {
+					continue
+				}
+
+				err = fn(c)
+				if err != nil {
+					return err
+				}
+			}
+
+			err = unstructured.SetNestedSlice(u, containers, append(path, field)...)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// mutateUnstructured calls fn with the unstructured content of obj. Typed
+// objects are converted back once fn returns.
+func mutateUnstructured(obj runtime.Object, fn func(u map[string]interface{}) error) error {
+	if u, ok := obj.(runtime.Unstructured); ok {
+		content := u.UnstructuredContent()
+		err := fn(content)
+		if err != nil {
+			return err
+		}
+
+		u.SetUnstructuredContent(content)
+		return nil
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return err
+	}
+
+	err = fn(content)
+	if err != nil {
+		return err
+	}
+
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(content, obj)
+}
@@ -0,0 +1,229 @@
+package template
+
+import (
+	"errors"
+	appsv1 "github.com/openshift/api/apps/v1"
+	"io/ioutil"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"strings"
+	"testing"
+)
+
+func unstructuredDeploymentConfig(t *testing.T) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	err := u.UnmarshalJSON([]byte(`{
+		"apiVersion": "apps.openshift.io/v1",
+		"kind": "DeploymentConfig",
+		"metadata": {"name": "app", "namespace": "hardcoded"},
+		"spec": {"template": {"spec": {
+			"containers": [{"name": "app", "image": "nginx:1.15"}],
+			"initContainers": [{"name": "init", "image": "quay.io/org/init:latest"}]
+		}}}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to decode deployment config: %v", err)
+	}
+
+	return u
+}
+
+func TestMutators(t *testing.T) {
+	typed := loadObjects(t, "_testdata/template-params.json")[0]
+
+	cases := []struct {
+		Name     string
+		Mutator  MutatorFn
+		Object   runtime.Object
+		Validate func(obj runtime.Object)
+	}{
+		{
+			Name:    "Should force the namespace of typed objects",
+			Mutator: MutateNamespace("forced", newTestMapper()),
+			Object:  typed.DeepCopyObject(),
+			Validate: func(obj runtime.Object) {
+				if obj.(*appsv1.DeploymentConfig).Namespace != "forced" {
+					t.Fatalf("Namespace should be forced: %v", obj)
+				}
+			},
+		},
+		{
+			Name:    "Should not set the namespace of cluster scoped objects",
+			Mutator: MutateNamespace("forced", newTestMapper()),
+			Object:  &corev1.Namespace{TypeMeta: metaType("v1", "Namespace")},
+			Validate: func(obj runtime.Object) {
+				if obj.(*corev1.Namespace).Namespace != "" {
+					t.Fatalf("Namespace should not be set: %v", obj)
+				}
+			},
+		},
+		{
+			Name:    "Should add labels and annotations",
+			Mutator: combineMutators(MutateLabels(map[string]string{"app": "forced", "team": "a"}), MutateAnnotations(map[string]string{"owner": "a"})),
+			Object:  unstructuredDeploymentConfig(t),
+			Validate: func(obj runtime.Object) {
+				u := obj.(*unstructured.Unstructured)
+				if u.GetLabels()["app"] != "forced" || u.GetLabels()["team"] != "a" || u.GetAnnotations()["owner"] != "a" {
+					t.Fatalf("Labels and annotations should be set: %v", u.Object["metadata"])
+				}
+			},
+		},
+		{
+			Name:    "Should inject image pull secrets in typed objects",
+			Mutator: MutateImagePullSecrets("registry", "registry"),
+			Object:  typed.DeepCopyObject(),
+			Validate: func(obj runtime.Object) {
+				secrets := obj.(*appsv1.DeploymentConfig).Spec.Template.Spec.ImagePullSecrets
+				if len(secrets) != 1 || secrets[0].Name != "registry" {
+					t.Fatalf("Image pull secret should be injected once: %v", secrets)
+				}
+			},
+		},
+		{
+			Name:    "Should inject image pull secrets in service accounts",
+			Mutator: MutateImagePullSecrets("registry"),
+			Object:  &corev1.ServiceAccount{TypeMeta: metaType("v1", "ServiceAccount"), ImagePullSecrets: []corev1.LocalObjectReference{{Name: "existing"}}},
+			Validate: func(obj runtime.Object) {
+				if len(obj.(*corev1.ServiceAccount).ImagePullSecrets) != 2 {
+					t.Fatalf("Image pull secret should be added: %v", obj)
+				}
+			},
+		},
+		{
+			Name: "Should override resource limits of unstructured objects",
+			Mutator: MutateResources(corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("512Mi")},
+			}),
+			Object: unstructuredDeploymentConfig(t),
+			Validate: func(obj runtime.Object) {
+				containers, _, _ := unstructured.NestedSlice(obj.(*unstructured.Unstructured).Object, "spec", "template", "spec", "initContainers")
+				memory, _, _ := unstructured.NestedString(containers[0].(map[string]interface{}), "resources", "limits", "memory")
+				if memory != "512Mi" {
+					t.Fatalf("Memory limit should be set: %v", containers)
+				}
+			},
+		},
+		{
+			Name: "Should override resource limits of typed objects",
+			Mutator: MutateResources(corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+			}),
+			Object: typed.DeepCopyObject(),
+			Validate: func(obj runtime.Object) {
+				cpu := obj.(*appsv1.DeploymentConfig).Spec.Template.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU]
+				if cpu.String() != "100m" {
+					t.Fatalf("Cpu request should be set: %s", cpu.String())
+				}
+			},
+		},
+		{
+			Name:    "Should rewrite image registries of typed objects",
+			Mutator: MutateImageRegistry("quay.io", "mirror.local:5000"),
+			Object:  typed.DeepCopyObject(),
+			Validate: func(obj runtime.Object) {
+				image := obj.(*appsv1.DeploymentConfig).Spec.Template.Spec.Containers[0].Image
+				if image != "mirror.local:5000/integreatly/params-app:latest" {
+					t.Fatalf("Image registry should be rewritten: %s", image)
+				}
+			},
+		},
+		{
+			Name:    "Should rewrite implicit docker.io images of unstructured objects",
+			Mutator: MutateImageRegistry("docker.io", "mirror.local"),
+			Object:  unstructuredDeploymentConfig(t),
+			Validate: func(obj runtime.Object) {
+				u := obj.(*unstructured.Unstructured).Object
+				containers, _, _ := unstructured.NestedSlice(u, "spec", "template", "spec", "containers")
+				initContainers, _, _ := unstructured.NestedSlice(u, "spec", "template", "spec", "initContainers")
+				if containers[0].(map[string]interface{})["image"] != "mirror.local/library/nginx:1.15" {
+					t.Fatalf("Image registry should be rewritten: %v", containers)
+				}
+				if initContainers[0].(map[string]interface{})["image"] != "quay.io/org/init:latest" {
+					t.Fatalf("Other registries should be kept: %v", initContainers)
+				}
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		err := tc.Mutator(tc.Object)
+		if err != nil {
+			t.Fatalf("\"%s\" did not expect error but got %s", tc.Name, err)
+		}
+
+		tc.Validate(tc.Object)
+	}
+}
+
+func TestTmpl_GetObjects_Mutators(t *testing.T) {
+	tmpl := &Tmpl{
+		Objects:  loadObjects(t, "_testdata/template-params.json"),
+		Mutators: []MutatorFn{MutateNamespace("forced", newTestMapper())},
+	}
+
+	for _, obj := range tmpl.GetObjects(NoFilterFn) {
+		u, _ := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if ns, _, _ := unstructured.NestedString(u, "metadata", "namespace"); ns != "forced" {
+			t.Fatalf("Mutators should be applied: %v", obj)
+		}
+	}
+
+	if tmpl.Objects[0].(*appsv1.DeploymentConfig).Namespace != "" {
+		t.Fatalf("Mutators should not modify the template objects")
+	}
+}
+
+func TestTmpl_GetObjectsWithError(t *testing.T) {
+	tmpl := &Tmpl{
+		Objects:  loadObjects(t, "_testdata/template-params.json"),
+		Mutators: []MutatorFn{MutateNamespace("forced", newTestMapper())},
+	}
+
+	objects, err := tmpl.GetObjectsWithError(NoFilterFn)
+	if err != nil || len(objects) != len(tmpl.Objects) {
+		t.Fatalf("Test failed: %v %v", objects, err)
+	}
+
+	tmpl.Mutators = append(tmpl.Mutators, func(obj runtime.Object) error {
+		if _, ok := obj.(*appsv1.DeploymentConfig); ok {
+			return errors.New("mutation failed")
+		}
+		return nil
+	})
+
+	objects, err = tmpl.GetObjectsWithError(NoFilterFn)
+	if err == nil || !strings.Contains(err.Error(), "DeploymentConfig params-app: mutation failed") {
+		t.Fatalf("Expected the mutation error but got %v", err)
+	}
+	if objects != nil {
+		t.Fatalf("Objects should not be returned on error: %v", objects)
+	}
+
+	err = tmpl.Render(ioutil.Discard, RenderYAML)
+	if err == nil {
+		t.Fatal("Render should fail when a mutator fails")
+	}
+
+	if len(tmpl.GetObjects(NoFilterFn)) != 0 {
+		t.Fatalf("GetObjects should not return a partial set of objects")
+	}
+}
+
+func combineMutators(mutators ...MutatorFn) MutatorFn {
+	return func(obj runtime.Object) error {
+		for _, mutator := range mutators {
+			err := mutator(obj)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func metaType(apiVersion, kind string) metav1.TypeMeta {
+	return metav1.TypeMeta{APIVersion: apiVersion, Kind: kind}
+}
@@ -0,0 +1,64 @@
+package template
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"sort"
+)
+
+var installOrder = [][]string{
+	{"Namespace", "Project", "ProjectRequest"},
+	{"CustomResourceDefinition"},
+	{"ResourceQuota", "LimitRange", "PodSecurityPolicy", "SecurityContextConstraints", "PriorityClass"},
+	{"ServiceAccount"},
+	{"ClusterRole", "Role", "ClusterRoleBinding", "RoleBinding", "RoleBindingRestriction"},
+	{"Secret", "ConfigMap", "StorageClass", "PersistentVolume", "PersistentVolumeClaim"},
+	{"ImageStream", "ImageStreamTag", "ImageStreamImport", "ImageStreamMapping"},
+	{"BuildConfig", "Build"},
+	{"Service", "Endpoints"},
+	{"Pod", "ReplicationController", "ReplicaSet", "Deployment", "DeploymentConfig", "StatefulSet", "DaemonSet", "Job", "CronJob"},
+	{"HorizontalPodAutoscaler", "PodDisruptionBudget", "NetworkPolicy"},
+	{"Route", "Ingress"},
+}
+
+var installRanks = func() map[string]int {
+	ranks := make(map[string]int)
+	for rank, kinds := range installOrder {
+		for _, kind := range kinds {
+			ranks[kind] = rank
+		}
+	}
+
+	return ranks
+}()
+
+// OrderFn sorts the objects in place.
+type OrderFn func(objects []runtime.Object)
+
+func NoOrderFn(objects []runtime.Object) {}
+
+// InstallOrder sorts objects so that the ones other objects depend on are
+// created first: namespaces and crds, then rbac and config, then image
+// streams and builds, then workloads and routes. Unknown kinds, like custom
+// resources, go last. Objects of the same rank keep their template order.
+func InstallOrder(objects []runtime.Object) {
+	sort.SliceStable(objects, func(i, j int) bool {
+		return installRank(objects[i]) < installRank(objects[j])
+	})
+}
+
+// DeleteOrder sorts objects in the exact reverse of InstallOrder.
+func DeleteOrder(objects []runtime.Object) {
+	InstallOrder(objects)
+
+	for i, j := 0, len(objects)-1; i < j; i, j = i+1, j-1 {
+		objects[i], objects[j] = objects[j], objects[i]
+	}
+}
+
+func installRank(obj runtime.Object) int {
+	if rank, ok := installRanks[obj.GetObjectKind().GroupVersionKind().Kind]; ok {
+		return rank
+	}
+
+	return len(installOrder)
+}
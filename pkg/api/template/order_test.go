@@ -0,0 +1,80 @@
+package template
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"testing"
+)
+
+func objectsOfKinds(kinds ...string) []runtime.Object {
+	objects := make([]runtime.Object, 0, len(kinds))
+	for _, kind := range kinds {
+		obj := &unstructured.Unstructured{}
+		obj.SetKind(kind)
+		obj.SetName(kind)
+		objects = append(objects, obj)
+	}
+
+	return objects
+}
+
+func kindsOf(objects []runtime.Object) []string {
+	kinds := make([]string, 0, len(objects))
+	for _, obj := range objects {
+		kinds = append(kinds, obj.GetObjectKind().GroupVersionKind().Kind)
+	}
+
+	return kinds
+}
+
+func TestOrderFn(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Order    OrderFn
+		Kinds    []string
+		Expected []string
+	}{
+		{
+			Name:     "Should keep template order",
+			Order:    NoOrderFn,
+			Kinds:    []string{"Route", "DeploymentConfig", "ServiceAccount"},
+			Expected: []string{"Route", "DeploymentConfig", "ServiceAccount"},
+		},
+		{
+			Name:     "Should sort in install order",
+			Order:    InstallOrder,
+			Kinds:    []string{"Route", "WebApp", "DeploymentConfig", "Service", "ImageStream", "Secret", "RoleBinding", "ServiceAccount", "CustomResourceDefinition", "Namespace"},
+			Expected: []string{"Namespace", "CustomResourceDefinition", "ServiceAccount", "RoleBinding", "Secret", "ImageStream", "Service", "DeploymentConfig", "Route", "WebApp"},
+		},
+		{
+			Name:     "Should keep template order within the same rank",
+			Order:    InstallOrder,
+			Kinds:    []string{"ConfigMap", "Secret", "ConfigMap"},
+			Expected: []string{"ConfigMap", "Secret", "ConfigMap"},
+		},
+		{
+			Name:     "Should sort in delete order",
+			Order:    DeleteOrder,
+			Kinds:    []string{"Route", "DeploymentConfig", "ServiceAccount", "Namespace", "WebApp"},
+			Expected: []string{"WebApp", "Route", "DeploymentConfig", "ServiceAccount", "Namespace"},
+		},
+	}
+
+	for _, tc := range cases {
+		tmpl := &Tmpl{
+			Objects: objectsOfKinds(tc.Kinds...),
+			Order:   tc.Order,
+		}
+
+		kinds := kindsOf(tmpl.GetObjects(NoFilterFn))
+		for i := range tc.Expected {
+			if kinds[i] != tc.Expected[i] {
+				t.Fatalf("\"%s\" expected %v but got %v", tc.Name, tc.Expected, kinds)
+			}
+		}
+
+		if len(kindsOf(tmpl.Objects)) != len(tc.Kinds) || kindsOf(tmpl.Objects)[0] != tc.Kinds[0] {
+			t.Fatalf("\"%s\" template objects should keep their order: %v", tc.Name, kindsOf(tmpl.Objects))
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package template
+
+import (
+	"context"
+	"fmt"
+	"github.com/integr8ly/operator-sdk-openshift-utils/pkg/api/kubernetes"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// OwnerLabel tracks the owner of the objects that can not carry an owner
+// reference to it, holding the owner uid.
+const OwnerLabel = "template.integreatly.org/owner"
+
+// Deleter deletes an object from the cluster. The dynamic client returned by
+// NewDynamicClient implements it; a controller-runtime client can be wrapped
+// with DeleterFunc.
+type Deleter interface {
+	Delete(ctx context.Context, obj runtime.Object) error
+}
+
+type DeleterFunc func(ctx context.Context, obj runtime.Object) error
+
+func (f DeleterFunc) Delete(ctx context.Context, obj runtime.Object) error {
+	return f(ctx, obj)
+}
+
+// SetOwner makes owner the controller of every object. Objects that live in
+// another namespace than a namespaced owner, and every object of a cluster
+// scoped owner, get the OwnerLabel instead since garbage collection does not
+// follow owner references across namespaces. Cluster scoped objects, as told
+// by mapper, get the OwnerLabel too, other objects without a namespace are
+// considered to be in ns.
+func SetOwner(objects []runtime.Object, owner metav1.Object, ownerGVK schema.GroupVersionKind, ns string, mapper meta.RESTMapper) error {
+	for _, obj := range objects {
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return err
+		}
+
+		objNs := accessor.GetNamespace()
+		if objNs == "" {
+			objNs = ns
+		}
+
+		namespaced, err := isNamespaced(mapper, obj)
+		if err != nil {
+			return err
+		}
+
+		if !namespaced || owner.GetNamespace() == "" || owner.GetNamespace() != objNs {
+			labels := accessor.GetLabels()
+			if labels == nil {
+				labels = make(map[string]string)
+			}
+			labels[OwnerLabel] = string(owner.GetUID())
+			accessor.SetLabels(labels)
+			continue
+		}
+
+		refs := make([]metav1.OwnerReference, 0)
+		for _, ref := range accessor.GetOwnerReferences() {
+			if ref.UID != owner.GetUID() {
+				refs = append(refs, ref)
+			}
+		}
+		accessor.SetOwnerReferences(append(refs, *metav1.NewControllerRef(owner, ownerGVK)))
+	}
+
+	return nil
+}
+
+// isNamespaced looks up the scope of the kind of obj in mapper.
+func isNamespaced(mapper meta.RESTMapper, obj runtime.Object) (bool, error) {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return false, err
+	}
+
+	return mapping.Scope.Name() == meta.RESTScopeNameNamespace, nil
+}
+
+// IsOwnedBy reports whether obj carries an owner reference or the
+// OwnerLabel pointing to owner.
+func IsOwnedBy(obj metav1.Object, owner metav1.Object) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID == owner.GetUID() {
+			return true
+		}
+	}
+
+	uid := string(owner.GetUID())
+	return uid != "" && obj.GetLabels()[OwnerLabel] == uid
+}
+
+// Prune deletes the objects of a previous render that the current render no
+// longer produces. Objects that are not owned by owner anymore are left
+// untouched.
+func Prune(ctx context.Context, client Client, deleter Deleter, owner metav1.Object, ns string, previous, current []runtime.Object) (ApplyReport, error) {
+	keep := make(map[string]bool)
+	for _, obj := range current {
+		u, err := kubernetes.UnstructuredFromRuntimeObject(obj)
+		if err != nil {
+			return nil, err
+		}
+		keep[objectKey(u, ns)] = true
+	}
+
+	report := make(ApplyReport, 0)
+	errs := make([]error, 0)
+
+	for _, obj := range previous {
+		u, err := kubernetes.UnstructuredFromRuntimeObject(obj)
+		if err != nil {
+			return nil, err
+		}
+
+		if keep[objectKey(u, ns)] {
+			continue
+		}
+
+		result := prune(ctx, client, deleter, owner, ns, u)
+		if result.Err != nil {
+			errs = append(errs, result.Err)
+		}
+		report = append(report, result)
+	}
+
+	return report, utilerrors.NewAggregate(errs)
+}
+
+func prune(ctx context.Context, client Client, deleter Deleter, owner metav1.Object, ns string, obj *unstructured.Unstructured) ApplyResult {
+	if obj.GetNamespace() == "" {
+		obj.SetNamespace(ns)
+	}
+
+	result := ApplyResult{
+		GroupVersionKind: obj.GroupVersionKind(),
+		Namespace:        obj.GetNamespace(),
+		Name:             obj.GetName(),
+		Action:           ApplyFailed,
+	}
+
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(obj.GroupVersionKind())
+
+	err := client.Get(ctx, types.NamespacedName{Namespace: result.Namespace, Name: result.Name}, existing)
+	if errors.IsNotFound(err) {
+		result.Action = ApplyUnchanged
+		return result
+	}
+	if err != nil {
+		result.Err = fmt.Errorf("failed to get %s %s/%s: %v", result.GroupVersionKind.Kind, result.Namespace, result.Name, err)
+		return result
+	}
+
+	if !IsOwnedBy(existing, owner) {
+		result.Action = ApplyUnchanged
+		return result
+	}
+
+	err = deleter.Delete(ctx, existing)
+	if err != nil && !errors.IsNotFound(err) {
+		result.Err = fmt.Errorf("failed to delete %s %s/%s: %v", result.GroupVersionKind.Kind, result.Namespace, result.Name, err)
+		return result
+	}
+
+	result.Action = ApplyDeleted
+	return result
+}
+
+func objectKey(obj *unstructured.Unstructured, ns string) string {
+	objNs := obj.GetNamespace()
+	if objNs == "" {
+		objNs = ns
+	}
+
+	gvk := obj.GroupVersionKind()
+	return gvk.Group + "/" + gvk.Kind + "/" + objNs + "/" + obj.GetName()
+}
@@ -0,0 +1,180 @@
+package template
+
+import (
+	"context"
+	"github.com/openshift/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"testing"
+)
+
+var ownerGVK = schema.GroupVersionKind{Group: "integreatly.org", Version: "v1alpha1", Kind: "WebApp"}
+
+func newOwner(ns string) *unstructured.Unstructured {
+	owner := &unstructured.Unstructured{}
+	owner.SetGroupVersionKind(ownerGVK)
+	owner.SetNamespace(ns)
+	owner.SetName("owner")
+	owner.SetUID("owner-uid")
+
+	return owner
+}
+
+// newTestMapper maps the kinds used by the tests to their scope.
+func newTestMapper() meta.RESTMapper {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	for _, gvk := range []schema.GroupVersionKind{
+		{Group: "apps.openshift.io", Version: "v1", Kind: "DeploymentConfig"},
+		{Group: "route.openshift.io", Version: "v1", Kind: "Route"},
+		{Version: "v1", Kind: "Service"},
+		{Version: "v1", Kind: "ConfigMap"},
+	} {
+		mapper.Add(gvk, meta.RESTScopeNamespace)
+	}
+
+	for _, gvk := range []schema.GroupVersionKind{
+		{Version: "v1", Kind: "Namespace"},
+		{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"},
+	} {
+		mapper.Add(gvk, meta.RESTScopeRoot)
+	}
+
+	return mapper
+}
+
+func TestSetOwner(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Owner    *unstructured.Unstructured
+		Validate func(objects []runtime.Object)
+	}{
+		{
+			Name:  "Should add owner reference in the owner namespace",
+			Owner: newOwner("test"),
+			Validate: func(objects []runtime.Object) {
+				dc := objects[0].(*v1.DeploymentConfig)
+				if len(dc.OwnerReferences) != 1 || dc.OwnerReferences[0].UID != "owner-uid" || !*dc.OwnerReferences[0].Controller {
+					t.Fatalf("Failed to set owner reference: %v", dc.OwnerReferences)
+				}
+				if _, ok := dc.Labels[OwnerLabel]; ok {
+					t.Fatalf("Owner label should not be set: %v", dc.Labels)
+				}
+
+				svc := objects[1].(*corev1.Service)
+				if len(svc.OwnerReferences) != 0 || svc.Labels[OwnerLabel] != "owner-uid" {
+					t.Fatalf("Object in another namespace should be labeled: %v", svc.ObjectMeta)
+				}
+
+				role := objects[3].(*rbacv1.ClusterRole)
+				if len(role.OwnerReferences) != 0 || role.Labels[OwnerLabel] != "owner-uid" {
+					t.Fatalf("Cluster scoped object should be labeled: %v", role.ObjectMeta)
+				}
+			},
+		},
+		{
+			Name:  "Should add owner label for cluster scoped owner",
+			Owner: newOwner(""),
+			Validate: func(objects []runtime.Object) {
+				for _, obj := range objects {
+					o := obj.(metav1.Object)
+					if len(o.GetOwnerReferences()) != 0 || o.GetLabels()[OwnerLabel] != "owner-uid" {
+						t.Fatalf("Failed to set owner label: %v", o)
+					}
+				}
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		objects := loadObjects(t, "_testdata/template-params.json")
+		objects[1].(*corev1.Service).Namespace = "other"
+		objects = append(objects, &rbacv1.ClusterRole{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole"},
+			ObjectMeta: metav1.ObjectMeta{Name: "owner-role"},
+		})
+
+		err := SetOwner(objects, tc.Owner, ownerGVK, "test", newTestMapper())
+		if err != nil {
+			t.Fatalf("\"%s\" did not expect error but got %s", tc.Name, err)
+		}
+
+		for _, obj := range objects {
+			if !IsOwnedBy(obj.(metav1.Object), tc.Owner) {
+				t.Fatalf("\"%s\" object should be owned: %v", tc.Name, obj)
+			}
+		}
+
+		tc.Validate(objects)
+	}
+}
+
+func TestSetOwner_UnknownKind(t *testing.T) {
+	objects := []runtime.Object{&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Unknown",
+		"metadata":   map[string]interface{}{"name": "unknown"},
+	}}}
+
+	err := SetOwner(objects, newOwner("test"), ownerGVK, "test", newTestMapper())
+	if err == nil {
+		t.Fatalf("Expected an error for a kind unknown to the mapper")
+	}
+}
+
+func TestPrune(t *testing.T) {
+	owner := newOwner("test")
+
+	previous := loadObjects(t, "_testdata/template-params.json")
+	err := SetOwner(previous, owner, ownerGVK, "test", newTestMapper())
+	if err != nil {
+		t.Fatalf("Test failed: %v", err)
+	}
+
+	client := newFakeClient()
+	_, err = NewApplier(client, "test").Apply(context.TODO(), previous)
+	if err != nil {
+		t.Fatalf("Test failed: %v", err)
+	}
+
+	unowned := &unstructured.Unstructured{}
+	unowned.SetAPIVersion("v1")
+	unowned.SetKind("ConfigMap")
+	unowned.SetNamespace("test")
+	unowned.SetName("unowned")
+	client.Create(context.TODO(), unowned)
+	previous = append(previous, unowned)
+
+	deleted := make([]string, 0)
+	deleter := DeleterFunc(func(ctx context.Context, obj runtime.Object) error {
+		u := obj.(*unstructured.Unstructured)
+		deleted = append(deleted, u.GetKind())
+		delete(client.objects, fakeKey(u.GetKind(), u.GetNamespace(), u.GetName()))
+		return nil
+	})
+
+	report, err := Prune(context.TODO(), client, deleter, owner, "test", previous, previous[:1])
+	if err != nil {
+		t.Fatalf("Test failed: %v", err)
+	}
+
+	if len(report) != 3 {
+		t.Fatalf("Expected a result per pruned object: %v", report)
+	}
+
+	if len(deleted) != 2 || deleted[0] != "Service" || deleted[1] != "Route" {
+		t.Fatalf("Unexpected deleted objects: %v", deleted)
+	}
+
+	if report[2].Action != ApplyUnchanged {
+		t.Fatalf("Unowned object should not be deleted: %v", report[2])
+	}
+
+	if _, ok := client.objects[fakeKey("DeploymentConfig", "test", "params-app")]; !ok {
+		t.Fatalf("Current object should not be deleted")
+	}
+}
@@ -0,0 +1,176 @@
+package template
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"os"
+	"strings"
+)
+
+// ParamValue is a parameter value along with a description of where it
+// was read from, e.g. "secret my-project/credentials".
+type ParamValue struct {
+	Value  string
+	Source string
+}
+
+// Params holds parameter values by parameter name.
+type Params map[string]ParamValue
+
+// Values returns the plain values, as accepted by Process and Validate.
+func (p Params) Values() map[string]string {
+	values := make(map[string]string, len(p))
+	for name, param := range p {
+		values[name] = param.Value
+	}
+
+	return values
+}
+
+// MergeParams merges layers of parameters, the values of a layer taking
+// precedence over the values of the layers before it.
+func MergeParams(layers ...Params) Params {
+	merged := make(Params)
+	for _, layer := range layers {
+		for name, param := range layer {
+			merged[name] = param
+		}
+	}
+
+	return merged
+}
+
+// ParamsFromMap wraps plain values, like the fields of a custom resource
+// spec, recording source as their origin.
+func ParamsFromMap(values map[string]string, source string) Params {
+	params := make(Params, len(values))
+	for name, value := range values {
+		params[name] = ParamValue{Value: value, Source: source}
+	}
+
+	return params
+}
+
+// ParamsFromEnvFile reads a .env file holding a KEY=VALUE pair per line.
+// Empty lines and comments are skipped, an export prefix is allowed and
+// values can be quoted.
+func ParamsFromEnvFile(path string) (Params, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	params := make(Params)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(strings.TrimPrefix(text, "export "), "=", 2)
+		name := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || name == "" {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE, got %q", path, line, text)
+		}
+
+		params[name] = ParamValue{
+			Value:  unquote(strings.TrimSpace(parts[1])),
+			Source: fmt.Sprintf("env file %s:%d", path, line),
+		}
+	}
+
+	return params, scanner.Err()
+}
+
+// ParamsFromEnv reads the environment variables starting with prefix, the
+// parameter name being the variable name without the prefix.
+func ParamsFromEnv(prefix string) Params {
+	params := make(Params)
+	for _, env := range os.Environ() {
+		parts := strings.SplitN(env, "=", 2)
+		if len(parts) != 2 || !strings.HasPrefix(parts[0], prefix) || parts[0] == prefix {
+			continue
+		}
+
+		params[strings.TrimPrefix(parts[0], prefix)] = ParamValue{
+			Value:  parts[1],
+			Source: "env " + parts[0],
+		}
+	}
+
+	return params
+}
+
+// ParamsFromConfigMap reads the data of a config map.
+func ParamsFromConfigMap(ctx context.Context, client Client, ns, name string) (Params, error) {
+	data, err := getData(ctx, client, "ConfigMap", ns, name)
+	if err != nil {
+		return nil, err
+	}
+
+	params := make(Params, len(data))
+	for key, value := range data {
+		params[key] = ParamValue{
+			Value:  value,
+			Source: fmt.Sprintf("configmap %s/%s", ns, name),
+		}
+	}
+
+	return params, nil
+}
+
+// ParamsFromSecret reads and decodes the data of a secret.
+func ParamsFromSecret(ctx context.Context, client Client, ns, name string) (Params, error) {
+	data, err := getData(ctx, client, "Secret", ns, name)
+	if err != nil {
+		return nil, err
+	}
+
+	params := make(Params, len(data))
+	for key, value := range data {
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode key %s of secret %s/%s: %v", key, ns, name, err)
+		}
+
+		params[key] = ParamValue{
+			Value:  string(decoded),
+			Source: fmt.Sprintf("secret %s/%s", ns, name),
+		}
+	}
+
+	return params, nil
+}
+
+func getData(ctx context.Context, client Client, kind, ns, name string) (map[string]string, error) {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind(kind)
+
+	err := client.Get(ctx, types.NamespacedName{Namespace: ns, Name: name}, obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s %s/%s: %v", kind, ns, name, err)
+	}
+
+	data, _, err := unstructured.NestedStringMap(obj.Object, "data")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data of %s %s/%s: %v", kind, ns, name, err)
+	}
+
+	return data, nil
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+
+	return value
+}
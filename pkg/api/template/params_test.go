@@ -0,0 +1,160 @@
+package template
+
+import (
+	"context"
+	"io/ioutil"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParamSources(t *testing.T) {
+	configMap := &unstructured.Unstructured{}
+	configMap.SetAPIVersion("v1")
+	configMap.SetKind("ConfigMap")
+	configMap.SetNamespace("test")
+	configMap.SetName("params")
+	unstructured.SetNestedStringMap(configMap.Object, map[string]string{"APP_NAME": "from-configmap"}, "data")
+
+	secret := &unstructured.Unstructured{}
+	secret.SetAPIVersion("v1")
+	secret.SetKind("Secret")
+	secret.SetNamespace("test")
+	secret.SetName("params")
+	unstructured.SetNestedStringMap(secret.Object, map[string]string{"PASSWORD": "c2VjcmV0"}, "data")
+
+	client := newFakeClient(configMap, secret)
+
+	os.Setenv("TMPL_PARAM_IMAGE", "from-env")
+	defer os.Unsetenv("TMPL_PARAM_IMAGE")
+
+	cases := []struct {
+		Name        string
+		Params      func() (Params, error)
+		Expected    Params
+		ExpectError bool
+	}{
+		{
+			Name: "Should read env files",
+			Params: func() (Params, error) {
+				return ParamsFromEnvFile("_testdata/params.env")
+			},
+			Expected: Params{
+				"APP_NAME":   {Value: "from-file", Source: "env file _testdata/params.env:2"},
+				"IMAGE_TAG":  {Value: "1.0", Source: "env file _testdata/params.env:3"},
+				"ROUTE_HOST": {Value: "params-app.example.com", Source: "env file _testdata/params.env:5"},
+			},
+			ExpectError: false,
+		},
+		{
+			Name: "Should fail on missing env files",
+			Params: func() (Params, error) {
+				return ParamsFromEnvFile("_testdata/missing.env")
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "Should read environment variables with a prefix",
+			Params: func() (Params, error) {
+				return ParamsFromEnv("TMPL_PARAM_"), nil
+			},
+			Expected: Params{
+				"IMAGE": {Value: "from-env", Source: "env TMPL_PARAM_IMAGE"},
+			},
+			ExpectError: false,
+		},
+		{
+			Name: "Should read config maps",
+			Params: func() (Params, error) {
+				return ParamsFromConfigMap(context.TODO(), client, "test", "params")
+			},
+			Expected: Params{
+				"APP_NAME": {Value: "from-configmap", Source: "configmap test/params"},
+			},
+			ExpectError: false,
+		},
+		{
+			Name: "Should read and decode secrets",
+			Params: func() (Params, error) {
+				return ParamsFromSecret(context.TODO(), client, "test", "params")
+			},
+			Expected: Params{
+				"PASSWORD": {Value: "secret", Source: "secret test/params"},
+			},
+			ExpectError: false,
+		},
+		{
+			Name: "Should fail on missing secrets",
+			Params: func() (Params, error) {
+				return ParamsFromSecret(context.TODO(), client, "test", "missing")
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "Should let later layers take precedence",
+			Params: func() (Params, error) {
+				return MergeParams(
+					ParamsFromMap(map[string]string{"APP_NAME": "default", "IMAGE": "default"}, "defaults"),
+					ParamsFromMap(map[string]string{"APP_NAME": "from-cr"}, "cr test/app"),
+				), nil
+			},
+			Expected: Params{
+				"APP_NAME": {Value: "from-cr", Source: "cr test/app"},
+				"IMAGE":    {Value: "default", Source: "defaults"},
+			},
+			ExpectError: false,
+		},
+	}
+
+	for _, tc := range cases {
+		params, err := tc.Params()
+
+		if tc.ExpectError && err == nil {
+			t.Fatalf("\"%s\" expected an error but got none", tc.Name)
+		}
+
+		if !tc.ExpectError && err != nil {
+			t.Fatalf("\"%s\" did not expect error but got %s", tc.Name, err)
+		}
+
+		if len(params) != len(tc.Expected) {
+			t.Fatalf("\"%s\" expected %v but got %v", tc.Name, tc.Expected, params)
+		}
+
+		for name, expected := range tc.Expected {
+			if params[name] != expected {
+				t.Fatalf("\"%s\" expected %s to be %v but got %v", tc.Name, name, expected, params[name])
+			}
+		}
+	}
+}
+
+func TestTmpl_ValidateParams(t *testing.T) {
+	b, err := ioutil.ReadFile("_testdata/template-params.json")
+	if err != nil {
+		t.Fatalf("Failed to open mock file: %v", err)
+	}
+
+	tmpl, err := New(nil, b)
+	if err != nil {
+		t.Fatalf("Failed to create template: %v", err)
+	}
+
+	params, err := ParamsFromEnvFile("_testdata/params.env")
+	if err != nil {
+		t.Fatalf("Failed to read params: %v", err)
+	}
+	params = MergeParams(params, ParamsFromMap(map[string]string{"UNKNOWN": "value"}, "cr test/app"))
+
+	err = tmpl.ValidateParams(params)
+	if err == nil || !strings.Contains(err.Error(), "parameter UNKNOWN from cr test/app") {
+		t.Fatalf("Validation errors should name the source: %v", err)
+	}
+
+	delete(params, "UNKNOWN")
+	err = tmpl.ValidateParams(params)
+	if err != nil {
+		t.Fatalf("Test failed: %v", err)
+	}
+}
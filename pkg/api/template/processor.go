@@ -0,0 +1,210 @@
+package template
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	v1template "github.com/openshift/api/template/v1"
+	"regexp"
+	"strings"
+)
+
+var (
+	parameterExp          = regexp.MustCompile(`\$\{([a-zA-Z0-9\_]+)\}`)
+	stringParameterExp    = regexp.MustCompile(`\$\{([a-zA-Z0-9\_]+?)\}`)
+	nonStringParameterExp = regexp.MustCompile(`^\$\{\{([a-zA-Z0-9\_]+)\}\}$`)
+)
+
+type visitorFn func(in string) (string, bool)
+
+// ProcessLocal renders the template the same way the processedtemplates
+// endpoint does, without contacting the api server.
+func (t *Tmpl) ProcessLocal(params map[string]string) error {
+	processed, err := t.fillParams(params)
+	if err != nil {
+		return err
+	}
+
+	err = processTemplate(processed)
+	if err != nil {
+		return err
+	}
+
+	err = t.fillObjects(processed.Objects)
+	if err != nil {
+		return err
+	}
+
+	t.Processed = processed
+
+	return nil
+}
+
+func processTemplate(tpl *v1template.Template) error {
+	params := make(map[string]v1template.Parameter)
+	for i, param := range tpl.Parameters {
+		if param.Required && len(param.Value) == 0 {
+			return fmt.Errorf("template.parameters[%d]: parameter %s is required and must be specified", i, param.Name)
+		}
+		params[param.Name] = param
+	}
+
+	visitor := func(in string) (string, bool) {
+		return substitute(params, in)
+	}
+
+	// labels are substituted before they are added to the objects, like
+	// the server does
+	labels := make(map[string]string, len(tpl.ObjectLabels))
+	for key, value := range tpl.ObjectLabels {
+		key, _ = substitute(params, key)
+		labels[key], _ = substitute(params, value)
+	}
+	if tpl.ObjectLabels != nil {
+		tpl.ObjectLabels = labels
+	}
+
+	for i, rawObject := range tpl.Objects {
+		obj, err := decodeRaw(rawObject.Raw)
+		if err != nil {
+			return fmt.Errorf("template.objects[%d]: %v", i, err)
+		}
+
+		stripNamespace(obj)
+
+		value, err := visitStrings(obj, visitor)
+		if err != nil {
+			return fmt.Errorf("template.objects[%d]: %v", i, err)
+		}
+
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("template.objects[%d]: object is not a json map", i)
+		}
+
+		addLabels(obj, tpl.ObjectLabels)
+
+		raw, err := json.Marshal(obj)
+		if err != nil {
+			return fmt.Errorf("template.objects[%d]: %v", i, err)
+		}
+
+		tpl.Objects[i].Raw = raw
+		tpl.Objects[i].Object = nil
+	}
+
+	tpl.Message, _ = substitute(params, tpl.Message)
+
+	return nil
+}
+
+func decodeRaw(raw []byte) (map[string]interface{}, error) {
+	obj := make(map[string]interface{})
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	err := decoder.Decode(&obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return obj, nil
+}
+
+func substitute(params map[string]v1template.Parameter, in string) (string, bool) {
+	out := in
+
+	for _, match := range nonStringParameterExp.FindAllStringSubmatch(in, -1) {
+		if param, ok := params[match[1]]; ok {
+			return strings.Replace(out, match[0], param.Value, 1), false
+		}
+	}
+
+	for _, match := range stringParameterExp.FindAllStringSubmatch(in, -1) {
+		if param, ok := params[match[1]]; ok {
+			out = strings.Replace(out, match[0], param.Value, 1)
+		}
+	}
+
+	return out, true
+}
+
+func visitStrings(value interface{}, visitor visitorFn) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			newKey, _ := visitor(key)
+			newItem, err := visitStrings(item, visitor)
+			if err != nil {
+				return nil, err
+			}
+			out[newKey] = newItem
+		}
+		return out, nil
+	case []interface{}:
+		for i, item := range v {
+			newItem, err := visitStrings(item, visitor)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = newItem
+		}
+		return v, nil
+	case string:
+		out, asString := visitor(v)
+		if asString {
+			return out, nil
+		}
+
+		var literal interface{}
+		decoder := json.NewDecoder(strings.NewReader(out))
+		decoder.UseNumber()
+		err := decoder.Decode(&literal)
+		if err != nil {
+			return nil, fmt.Errorf("invalid json literal %q: %v", out, err)
+		}
+		return literal, nil
+	}
+
+	return value, nil
+}
+
+func stripNamespace(obj map[string]interface{}) {
+	metadata, ok := obj["metadata"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	ns, ok := metadata["namespace"].(string)
+	if !ok {
+		return
+	}
+
+	if !parameterExp.MatchString(strings.TrimSpace(ns)) {
+		delete(metadata, "namespace")
+	}
+}
+
+func addLabels(obj map[string]interface{}, labels map[string]string) {
+	if len(labels) == 0 {
+		return
+	}
+
+	metadata, ok := obj["metadata"].(map[string]interface{})
+	if !ok {
+		metadata = make(map[string]interface{})
+		obj["metadata"] = metadata
+	}
+
+	objLabels, ok := metadata["labels"].(map[string]interface{})
+	if !ok {
+		objLabels = make(map[string]interface{})
+		metadata["labels"] = objLabels
+	}
+
+	for key, value := range labels {
+		objLabels[key] = value
+	}
+}
@@ -0,0 +1,223 @@
+package template
+
+import (
+	"github.com/openshift/api/apps/v1"
+	v1template "github.com/openshift/api/template/v1"
+	"io/ioutil"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"reflect"
+	"testing"
+)
+
+func TestTmpl_ProcessLocal(t *testing.T) {
+	cases := []struct {
+		Name        string
+		Path        string
+		Params      map[string]string
+		Validate    func(tmpl *Tmpl)
+		ExpectError bool
+	}{
+		{
+			Name: "Should process template locally",
+			Path: "_testdata/template-params.json",
+			Params: map[string]string{
+				"APP_NAME": "my-app",
+				"REPLICAS": "3",
+			},
+			Validate: func(tmpl *Tmpl) {
+				if len(tmpl.Objects) != 3 {
+					t.Fatalf("Failed to fill template objects: %v", tmpl.Objects)
+				}
+
+				dc := tmpl.Objects[0].(*v1.DeploymentConfig)
+				if dc.Name != "my-app" {
+					t.Fatalf("Failed to substitute name: %s", dc.Name)
+				}
+				if dc.Namespace != "" {
+					t.Fatalf("Hardcoded namespace should be stripped: %s", dc.Namespace)
+				}
+				if dc.Spec.Replicas != 3 {
+					t.Fatalf("Failed to substitute non string parameter: %d", dc.Spec.Replicas)
+				}
+				if dc.Spec.Template.Spec.Containers[0].Image != "quay.io/integreatly/params-app:latest" {
+					t.Fatalf("Failed to substitute image: %s", dc.Spec.Template.Spec.Containers[0].Image)
+				}
+				if dc.Labels["template"] != "params-app" || dc.Labels["app"] != "my-app" {
+					t.Fatalf("Failed to apply template labels: %v", dc.Labels)
+				}
+
+				svc := tmpl.Objects[1].(*corev1.Service)
+				if svc.Namespace != "params-ns" {
+					t.Fatalf("Parameterized namespace should be kept: %s", svc.Namespace)
+				}
+
+				if tmpl.Processed.Message != "Application my-app deployed" {
+					t.Fatalf("Failed to substitute message: %s", tmpl.Processed.Message)
+				}
+			},
+			ExpectError: false,
+		},
+		{
+			Name: "Should fail on invalid json literal",
+			Path: "_testdata/template-params.json",
+			Params: map[string]string{
+				"REPLICAS": "{three",
+			},
+			Validate:    func(tmpl *Tmpl) {},
+			ExpectError: true,
+		},
+		{
+			Name: "Should fail on missing required value",
+			Path: "_testdata/template-params.json",
+			Params: map[string]string{
+				"APP_NAME": "",
+			},
+			Validate:    func(tmpl *Tmpl) {},
+			ExpectError: true,
+		},
+	}
+
+	for _, tc := range cases {
+		b, err := ioutil.ReadFile(tc.Path)
+		if err != nil {
+			t.Fatalf("Failed to open mock file: %v", err)
+		}
+
+		tmpl, err := New(nil, b)
+		if err != nil {
+			t.Fatalf("Failed to create template: %v", err)
+		}
+
+		err = tmpl.ProcessLocal(tc.Params)
+
+		if tc.ExpectError && err == nil {
+			t.Fatalf("\"%s\" expected an error but got none", tc.Name)
+		}
+
+		if !tc.ExpectError && err != nil {
+			t.Fatalf("\"%s\" did not expect error but got %s", tc.Name, err)
+		}
+
+		tc.Validate(tmpl)
+	}
+}
+
+func TestTmpl_ProcessLocal_Labels(t *testing.T) {
+	tmpl := &Tmpl{
+		Source: &v1template.Template{
+			ObjectLabels: map[string]string{
+				"app":          "${NAME}",
+				"${LABEL_KEY}": "${{VERSION}}",
+			},
+			Parameters: []v1template.Parameter{
+				{Name: "NAME", Value: "web"},
+				{Name: "LABEL_KEY", Value: "version"},
+				{Name: "VERSION", Value: "2"},
+			},
+			Objects: []runtime.RawExtension{
+				{Raw: []byte(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "config"}}`)},
+			},
+		},
+	}
+
+	err := tmpl.ProcessLocal(map[string]string{})
+	if err != nil {
+		t.Fatalf("Test failed: %v", err)
+	}
+
+	expected := map[string]string{"app": "web", "version": "2"}
+	if labels := tmpl.Objects[0].(*corev1.ConfigMap).Labels; !reflect.DeepEqual(labels, expected) {
+		t.Fatalf("Template labels should be substituted: %v", labels)
+	}
+
+	if tmpl.Source.ObjectLabels["app"] != "${NAME}" {
+		t.Fatalf("Template source should not be modified: %v", tmpl.Source.ObjectLabels)
+	}
+}
+
+func TestSubstitute(t *testing.T) {
+	params := map[string]v1template.Parameter{
+		"FOO": {Name: "FOO", Value: "foo"},
+		"BAR": {Name: "BAR", Value: "bar"},
+		"NUM": {Name: "NUM", Value: "10"},
+	}
+
+	cases := []struct {
+		Name     string
+		Input    string
+		Output   string
+		AsString bool
+	}{
+		{
+			Name:     "Should substitute single parameter",
+			Input:    "${FOO}",
+			Output:   "foo",
+			AsString: true,
+		},
+		{
+			Name:     "Should substitute multiple parameters",
+			Input:    "${FOO}-${BAR}-${FOO}",
+			Output:   "foo-bar-foo",
+			AsString: true,
+		},
+		{
+			Name:     "Should keep unknown parameters",
+			Input:    "${FOO}-${UNKNOWN}",
+			Output:   "foo-${UNKNOWN}",
+			AsString: true,
+		},
+		{
+			Name:     "Should substitute non string parameter",
+			Input:    "${{NUM}}",
+			Output:   "10",
+			AsString: false,
+		},
+		{
+			Name:     "Should treat embedded non string parameter as string",
+			Input:    "x${{NUM}}",
+			Output:   "x${{NUM}}",
+			AsString: true,
+		},
+	}
+
+	for _, tc := range cases {
+		out, asString := substitute(params, tc.Input)
+		if out != tc.Output || asString != tc.AsString {
+			t.Fatalf("\"%s\" got (%s, %v), expected (%s, %v)", tc.Name, out, asString, tc.Output, tc.AsString)
+		}
+	}
+}
+
+func TestTmpl_ProcessLocalTwice(t *testing.T) {
+	b, err := ioutil.ReadFile("_testdata/template-params.json")
+	if err != nil {
+		t.Fatalf("Failed to open mock file: %v", err)
+	}
+
+	tmpl, err := New(nil, b)
+	if err != nil {
+		t.Fatalf("Failed to create template: %v", err)
+	}
+	source := tmpl.Source.DeepCopy()
+
+	for _, name := range []string{"first-app", "second-app"} {
+		err = tmpl.ProcessLocal(map[string]string{"APP_NAME": name})
+		if err != nil {
+			t.Fatalf("Test failed: %v", err)
+		}
+
+		if len(tmpl.Objects) != 3 {
+			t.Fatalf("Objects should be replaced on each call: %v", tmpl.Objects)
+		}
+
+		dc := tmpl.Objects[0].(*v1.DeploymentConfig)
+		if dc.Name != name {
+			t.Fatalf("Failed to process template with new parameters: %s", dc.Name)
+		}
+
+		if !reflect.DeepEqual(source, tmpl.Source) {
+			t.Fatalf("Template source should not be modified: %v", tmpl.Source)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package template
+
+import (
+	"context"
+	"fmt"
+	"github.com/integr8ly/operator-sdk-openshift-utils/pkg/api/kubernetes"
+	appsv1 "github.com/openshift/api/apps/v1"
+	buildv1 "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"strings"
+	"time"
+)
+
+const DefaultWaitInterval = 2 * time.Second
+
+// ReadyCheckFn reports whether obj, as read from the cluster, is ready and
+// why not. An error means the object will never become ready.
+type ReadyCheckFn func(obj *unstructured.Unstructured) (bool, string, error)
+
+var readyChecks = map[string]ReadyCheckFn{
+	"DeploymentConfig": deploymentConfigReady,
+	"Build":            buildReady,
+	"ImageStream":      imageStreamReady,
+	"Route":            routeReady,
+}
+
+type ReadyEvent struct {
+	GroupVersionKind schema.GroupVersionKind
+	Namespace        string
+	Name             string
+	Ready            bool
+	Message          string
+}
+
+// Waiter polls the objects of a template until they are ready.
+type Waiter struct {
+	Client    Client
+	Namespace string
+	Interval  time.Duration
+}
+
+func NewWaiter(client Client, ns string) *Waiter {
+	return &Waiter{
+		Client:    client,
+		Namespace: ns,
+		Interval:  DefaultWaitInterval,
+	}
+}
+
+// IsReady reports whether obj is ready. Deployment configs must have their
+// latest version rolled out and available, builds must be complete, image
+// streams must have every tag resolved and routes must be admitted. Any
+// other object is ready as soon as it exists.
+func IsReady(obj *unstructured.Unstructured) (bool, string, error) {
+	check, ok := readyChecks[obj.GetKind()]
+	if !ok {
+		return true, "", nil
+	}
+
+	return check(obj)
+}
+
+// Wait blocks until every object is ready, one of them fails or ctx is done.
+// An event is sent on events, when not nil, every time the state of an
+// object changes.
+func (w *Waiter) Wait(ctx context.Context, objects []runtime.Object, events chan<- ReadyEvent) error {
+	pending := make([]*unstructured.Unstructured, 0, len(objects))
+	for _, obj := range objects {
+		u, err := kubernetes.UnstructuredFromRuntimeObject(obj)
+		if err != nil {
+			return err
+		}
+		if u.GetNamespace() == "" {
+			u.SetNamespace(w.Namespace)
+		}
+		pending = append(pending, u)
+	}
+
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultWaitInterval
+	}
+
+	last := make(map[string]ReadyEvent)
+	for {
+		remaining := make([]*unstructured.Unstructured, 0, len(pending))
+		for _, obj := range pending {
+			event, err := w.check(ctx, obj)
+			if err != nil {
+				return err
+			}
+
+			key := objectKey(obj, w.Namespace)
+			if previous, ok := last[key]; !ok || previous != event {
+				last[key] = event
+				if err := sendEvent(ctx, events, event); err != nil {
+					return err
+				}
+			}
+
+			if !event.Ready {
+				remaining = append(remaining, obj)
+			}
+		}
+
+		if len(remaining) == 0 {
+			return nil
+		}
+		pending = remaining
+
+		select {
+		case <-ctx.Done():
+			names := make([]string, 0, len(pending))
+			for _, obj := range pending {
+				names = append(names, fmt.Sprintf("%s %s/%s (%s)", obj.GetKind(), obj.GetNamespace(), obj.GetName(), last[objectKey(obj, w.Namespace)].Message))
+			}
+			return fmt.Errorf("timed out waiting for %s: %v", strings.Join(names, ", "), ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (w *Waiter) check(ctx context.Context, obj *unstructured.Unstructured) (ReadyEvent, error) {
+	event := ReadyEvent{
+		GroupVersionKind: obj.GroupVersionKind(),
+		Namespace:        obj.GetNamespace(),
+		Name:             obj.GetName(),
+	}
+
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(obj.GroupVersionKind())
+
+	err := w.Client.Get(ctx, types.NamespacedName{Namespace: event.Namespace, Name: event.Name}, existing)
+	if errors.IsNotFound(err) {
+		event.Message = "not found"
+		return event, nil
+	}
+	if err != nil {
+		return event, fmt.Errorf("failed to get %s %s/%s: %v", event.GroupVersionKind.Kind, event.Namespace, event.Name, err)
+	}
+
+	event.Ready, event.Message, err = IsReady(existing)
+	if err != nil {
+		return event, fmt.Errorf("%s %s/%s failed: %v", event.GroupVersionKind.Kind, event.Namespace, event.Name, err)
+	}
+
+	return event, nil
+}
+
+func sendEvent(ctx context.Context, events chan<- ReadyEvent, event ReadyEvent) error {
+	if events == nil {
+		return nil
+	}
+
+	select {
+	case events <- event:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func deploymentConfigReady(obj *unstructured.Unstructured) (bool, string, error) {
+	dc := &appsv1.DeploymentConfig{}
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, dc)
+	if err != nil {
+		return false, "", err
+	}
+
+	if dc.Status.ObservedGeneration < dc.Generation {
+		return false, "waiting for the latest generation to be observed", nil
+	}
+
+	if dc.Status.LatestVersion == 0 {
+		return false, "waiting for the first rollout", nil
+	}
+
+	var progressing, available *appsv1.DeploymentCondition
+	for i := range dc.Status.Conditions {
+		switch dc.Status.Conditions[i].Type {
+		case appsv1.DeploymentProgressing:
+			progressing = &dc.Status.Conditions[i]
+		case appsv1.DeploymentAvailable:
+			available = &dc.Status.Conditions[i]
+		}
+	}
+
+	if progressing != nil && progressing.Status == corev1.ConditionFalse && progressing.Reason == string(appsv1.ProgressDeadlineExceededReason) {
+		return false, "", fmt.Errorf("rollout of version %d failed: %s", dc.Status.LatestVersion, progressing.Message)
+	}
+
+	if progressing == nil || progressing.Status != corev1.ConditionTrue || progressing.Reason != string(appsv1.NewReplicationControllerAvailableReason) {
+		return false, fmt.Sprintf("waiting for rollout of version %d", dc.Status.LatestVersion), nil
+	}
+
+	if available == nil || available.Status != corev1.ConditionTrue {
+		return false, fmt.Sprintf("waiting for version %d to become available", dc.Status.LatestVersion), nil
+	}
+
+	if dc.Status.UpdatedReplicas < dc.Spec.Replicas || dc.Status.AvailableReplicas < dc.Spec.Replicas {
+		return false, fmt.Sprintf("%d of %d replicas available", dc.Status.AvailableReplicas, dc.Spec.Replicas), nil
+	}
+
+	return true, fmt.Sprintf("version %d available", dc.Status.LatestVersion), nil
+}
+
+func buildReady(obj *unstructured.Unstructured) (bool, string, error) {
+	build := &buildv1.Build{}
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, build)
+	if err != nil {
+		return false, "", err
+	}
+
+	switch build.Status.Phase {
+	case buildv1.BuildPhaseComplete:
+		return true, "build complete", nil
+	case buildv1.BuildPhaseFailed, buildv1.BuildPhaseError, buildv1.BuildPhaseCancelled:
+		return false, "", fmt.Errorf("build %s: %s", strings.ToLower(string(build.Status.Phase)), build.Status.Message)
+	}
+
+	phase := build.Status.Phase
+	if phase == "" {
+		phase = buildv1.BuildPhaseNew
+	}
+
+	return false, fmt.Sprintf("build %s", strings.ToLower(string(phase))), nil
+}
+
+func imageStreamReady(obj *unstructured.Unstructured) (bool, string, error) {
+	is := &imagev1.ImageStream{}
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, is)
+	if err != nil {
+		return false, "", err
+	}
+
+	resolved := make(map[string]bool)
+	for _, tag := range is.Status.Tags {
+		resolved[tag.Tag] = len(tag.Items) > 0
+	}
+
+	unresolved := make([]string, 0)
+	for _, tag := range is.Spec.Tags {
+		if !resolved[tag.Name] {
+			unresolved = append(unresolved, tag.Name)
+		}
+	}
+
+	if len(unresolved) > 0 {
+		return false, fmt.Sprintf("waiting for tags %s", strings.Join(unresolved, ", ")), nil
+	}
+
+	return true, "tags resolved", nil
+}
+
+func routeReady(obj *unstructured.Unstructured) (bool, string, error) {
+	route := &routev1.Route{}
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, route)
+	if err != nil {
+		return false, "", err
+	}
+
+	for _, ingress := range route.Status.Ingress {
+		for _, condition := range ingress.Conditions {
+			if condition.Type != routev1.RouteAdmitted {
+				continue
+			}
+
+			switch condition.Status {
+			case corev1.ConditionTrue:
+				return true, fmt.Sprintf("admitted by %s", ingress.RouterName), nil
+			case corev1.ConditionFalse:
+				return false, "", fmt.Errorf("rejected by %s: %s", ingress.RouterName, condition.Message)
+			}
+		}
+	}
+
+	return false, "waiting for the route to be admitted", nil
+}
@@ -0,0 +1,225 @@
+package template
+
+import (
+	"context"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"testing"
+	"time"
+)
+
+func newReadyObject(apiVersion, kind, name string, status map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace("test")
+	obj.SetName(name)
+	if status != nil {
+		obj.Object["status"] = status
+	}
+
+	return obj
+}
+
+func availableDeploymentConfig(replicas int64) *unstructured.Unstructured {
+	dc := newReadyObject("apps.openshift.io/v1", "DeploymentConfig", "app", map[string]interface{}{
+		"latestVersion":     int64(2),
+		"updatedReplicas":   replicas,
+		"availableReplicas": replicas,
+		"conditions": []interface{}{
+			map[string]interface{}{"type": "Available", "status": "True"},
+			map[string]interface{}{"type": "Progressing", "status": "True", "reason": "NewReplicationControllerAvailable"},
+		},
+	})
+	unstructured.SetNestedField(dc.Object, int64(1), "spec", "replicas")
+
+	return dc
+}
+
+func TestIsReady(t *testing.T) {
+	scaling := availableDeploymentConfig(0)
+
+	cases := []struct {
+		Name        string
+		Object      *unstructured.Unstructured
+		Ready       bool
+		ExpectError bool
+	}{
+		{
+			Name:   "Should be ready when the latest version is available",
+			Object: availableDeploymentConfig(1),
+			Ready:  true,
+		},
+		{
+			Name:   "Should wait for the replicas of the latest version",
+			Object: scaling,
+			Ready:  false,
+		},
+		{
+			Name:   "Should wait for the first rollout",
+			Object: newReadyObject("v1", "DeploymentConfig", "app", nil),
+			Ready:  false,
+		},
+		{
+			Name: "Should fail when the rollout exceeded its deadline",
+			Object: newReadyObject("apps.openshift.io/v1", "DeploymentConfig", "app", map[string]interface{}{
+				"latestVersion": int64(1),
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Progressing", "status": "False", "reason": "ProgressDeadlineExceeded"},
+				},
+			}),
+			ExpectError: true,
+		},
+		{
+			Name:   "Should be ready when the build is complete",
+			Object: newReadyObject("build.openshift.io/v1", "Build", "app-1", map[string]interface{}{"phase": "Complete"}),
+			Ready:  true,
+		},
+		{
+			Name:   "Should wait for a running build",
+			Object: newReadyObject("build.openshift.io/v1", "Build", "app-1", map[string]interface{}{"phase": "Running"}),
+			Ready:  false,
+		},
+		{
+			Name:        "Should fail when the build failed",
+			Object:      newReadyObject("build.openshift.io/v1", "Build", "app-1", map[string]interface{}{"phase": "Failed"}),
+			ExpectError: true,
+		},
+		{
+			Name: "Should wait for unresolved image stream tags",
+			Object: func() *unstructured.Unstructured {
+				is := newReadyObject("image.openshift.io/v1", "ImageStream", "app", map[string]interface{}{
+					"tags": []interface{}{
+						map[string]interface{}{"tag": "latest", "items": []interface{}{map[string]interface{}{"image": "sha256:1"}}},
+					},
+				})
+				unstructured.SetNestedSlice(is.Object, []interface{}{
+					map[string]interface{}{"name": "latest"},
+					map[string]interface{}{"name": "stable"},
+				}, "spec", "tags")
+				return is
+			}(),
+			Ready: false,
+		},
+		{
+			Name: "Should be ready when the route is admitted",
+			Object: newReadyObject("route.openshift.io/v1", "Route", "app", map[string]interface{}{
+				"ingress": []interface{}{
+					map[string]interface{}{
+						"routerName": "default",
+						"conditions": []interface{}{map[string]interface{}{"type": "Admitted", "status": "True"}},
+					},
+				},
+			}),
+			Ready: true,
+		},
+		{
+			Name: "Should fail when the route is rejected",
+			Object: newReadyObject("route.openshift.io/v1", "Route", "app", map[string]interface{}{
+				"ingress": []interface{}{
+					map[string]interface{}{
+						"routerName": "default",
+						"conditions": []interface{}{map[string]interface{}{"type": "Admitted", "status": "False", "reason": "HostAlreadyClaimed"}},
+					},
+				},
+			}),
+			ExpectError: true,
+		},
+		{
+			Name:   "Should wait for the route to be admitted",
+			Object: newReadyObject("route.openshift.io/v1", "Route", "app", nil),
+			Ready:  false,
+		},
+		{
+			Name:   "Should be ready when other objects exist",
+			Object: newReadyObject("v1", "Service", "app", nil),
+			Ready:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		ready, message, err := IsReady(tc.Object)
+
+		if tc.ExpectError && err == nil {
+			t.Fatalf("\"%s\" expected an error but got none", tc.Name)
+		}
+
+		if !tc.ExpectError && err != nil {
+			t.Fatalf("\"%s\" did not expect error but got %s", tc.Name, err)
+		}
+
+		if ready != tc.Ready {
+			t.Fatalf("\"%s\" expected ready to be %v: %s", tc.Name, tc.Ready, message)
+		}
+	}
+}
+
+func TestWaiter_Wait(t *testing.T) {
+	build := newReadyObject("build.openshift.io/v1", "Build", "app-1", map[string]interface{}{"phase": "Complete"})
+	route := newReadyObject("route.openshift.io/v1", "Route", "app", nil)
+	failed := newReadyObject("build.openshift.io/v1", "Build", "app-1", map[string]interface{}{"phase": "Error"})
+
+	cases := []struct {
+		Name        string
+		Client      *fakeClient
+		Events      int
+		ExpectError bool
+	}{
+		{
+			Name:        "Should return once every object is ready",
+			Client:      newFakeClient(availableDeploymentConfig(1), build.DeepCopy()),
+			Events:      2,
+			ExpectError: false,
+		},
+		{
+			Name:        "Should time out when an object is not ready",
+			Client:      newFakeClient(availableDeploymentConfig(1), route.DeepCopy()),
+			Events:      2,
+			ExpectError: true,
+		},
+		{
+			Name:        "Should time out when an object is missing",
+			Client:      newFakeClient(availableDeploymentConfig(1)),
+			Events:      2,
+			ExpectError: true,
+		},
+		{
+			Name:        "Should fail when an object failed",
+			Client:      newFakeClient(availableDeploymentConfig(1), failed.DeepCopy()),
+			Events:      0,
+			ExpectError: true,
+		},
+	}
+
+	for _, tc := range cases {
+		objects := make([]runtime.Object, 0)
+		for _, obj := range tc.Client.objects {
+			objects = append(objects, obj.DeepCopy())
+		}
+		if len(objects) == 1 {
+			objects = append(objects, route.DeepCopy())
+		}
+		InstallOrder(objects)
+
+		ctx, cancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+		events := make(chan ReadyEvent, 10)
+
+		waiter := NewWaiter(tc.Client, "test")
+		waiter.Interval = 10 * time.Millisecond
+		err := waiter.Wait(ctx, objects, events)
+		cancel()
+		close(events)
+
+		if tc.ExpectError && err == nil {
+			t.Fatalf("\"%s\" expected an error but got none", tc.Name)
+		}
+
+		if !tc.ExpectError && err != nil {
+			t.Fatalf("\"%s\" did not expect error but got %s", tc.Name, err)
+		}
+
+		if len(events) != tc.Events {
+			t.Fatalf("\"%s\" expected %d events but got %d", tc.Name, tc.Events, len(events))
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package template
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/ghodss/yaml"
+	"github.com/integr8ly/operator-sdk-openshift-utils/pkg/api/kubernetes"
+	"io"
+)
+
+type RenderFormat string
+
+const (
+	// RenderJSON writes the objects as an indented v1 List.
+	RenderJSON RenderFormat = "json"
+	// RenderYAML writes the objects as a v1 List in yaml.
+	RenderYAML RenderFormat = "yaml"
+	// RenderYAMLStream writes every object as a document of a yaml stream.
+	RenderYAMLStream RenderFormat = "yaml-stream"
+)
+
+type RenderOpt struct {
+	StripEmptyStatus        bool
+	StripCreationTimestamps bool
+}
+
+var RenderDefaultOpts = RenderOpt{
+	StripEmptyStatus:        true,
+	StripCreationTimestamps: true,
+}
+
+// Render writes the objects returned by GetObjectsWithError in format,
+// using RenderDefaultOpts.
+func (t *Tmpl) Render(w io.Writer, format RenderFormat) error {
+	return t.RenderWithOptions(w, format, RenderDefaultOpts)
+}
+
+// RenderWithOptions writes the objects returned by GetObjectsWithError in
+// format. Keys are sorted so that rendering the same objects always produces
+// the same output.
+func (t *Tmpl) RenderWithOptions(w io.Writer, format RenderFormat, opts RenderOpt) error {
+	objects, err := t.GetObjectsWithError(NoFilterFn)
+	if err != nil {
+		return err
+	}
+
+	items := make([]interface{}, 0, len(objects))
+	for _, obj := range objects {
+		u, err := kubernetes.UnstructuredFromRuntimeObject(obj)
+		if err != nil {
+			return err
+		}
+
+		if opts.StripEmptyStatus && isZeroValue(u.Object["status"]) {
+			delete(u.Object, "status")
+		}
+		if opts.StripCreationTimestamps {
+			stripCreationTimestamps(u.Object)
+		}
+
+		items = append(items, u.Object)
+	}
+
+	list := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"items":      items,
+	}
+
+	switch format {
+	case RenderJSON:
+		data, err := json.MarshalIndent(list, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(data, '\n'))
+		return err
+	case RenderYAML:
+		data, err := yaml.Marshal(list)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	case RenderYAMLStream:
+		buf := &bytes.Buffer{}
+		for _, item := range items {
+			data, err := yaml.Marshal(item)
+			if err != nil {
+				return err
+			}
+			buf.WriteString("---\n")
+			buf.Write(data)
+		}
+		_, err := w.Write(buf.Bytes())
+		return err
+	}
+
+	return fmt.Errorf("unknown render format %s", format)
+}
+
+// stripCreationTimestamps removes the empty creation timestamps of the
+// object and of its embedded templates.
+func stripCreationTimestamps(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if metadata, ok := field.(map[string]interface{}); ok && key == "metadata" {
+				if ts, found := metadata["creationTimestamp"]; found && isEmptyValue(ts) {
+					delete(metadata, "creationTimestamp")
+				}
+			}
+			stripCreationTimestamps(field)
+		}
+	case []interface{}:
+		for _, item := range v {
+			stripCreationTimestamps(item)
+		}
+	}
+}
+
+// isZeroValue reports whether value only holds zero values, like the status
+// of a typed object that was never written by the server.
+func isZeroValue(value interface{}) bool {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, field := range v {
+			if !isZeroValue(field) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		return len(v) == 0
+	case bool:
+		return !v
+	}
+
+	if n, ok := toFloat(value); ok {
+		return n == 0
+	}
+
+	return isEmptyValue(value)
+}
@@ -0,0 +1,95 @@
+package template
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/ghodss/yaml"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestTmpl_Render(t *testing.T) {
+	tmpl := &Tmpl{Objects: loadObjects(t, "_testdata/template-params.json")}
+
+	cases := []struct {
+		Name        string
+		Format      RenderFormat
+		Opts        RenderOpt
+		Validate    func(out string)
+		ExpectError bool
+	}{
+		{
+			Name:   "Should render a yaml stream",
+			Format: RenderYAMLStream,
+			Opts:   RenderDefaultOpts,
+			Validate: func(out string) {
+				expected, err := ioutil.ReadFile("_testdata/template-params-rendered.yaml")
+				if err != nil {
+					t.Fatalf("Failed to open mock file: %v", err)
+				}
+
+				if out != string(expected) {
+					t.Fatalf("Unexpected output:\n%s", out)
+				}
+			},
+			ExpectError: false,
+		},
+		{
+			Name:   "Should render a json list",
+			Format: RenderJSON,
+			Opts:   RenderDefaultOpts,
+			Validate: func(out string) {
+				list := make(map[string]interface{})
+				err := json.Unmarshal([]byte(out), &list)
+				if err != nil {
+					t.Fatalf("Output should be valid json: %v", err)
+				}
+
+				if list["kind"] != "List" || len(list["items"].([]interface{})) != 3 {
+					t.Fatalf("Output should be a list of the objects: %v", list)
+				}
+			},
+			ExpectError: false,
+		},
+		{
+			Name:   "Should render a yaml list keeping empty fields",
+			Format: RenderYAML,
+			Opts:   RenderOpt{},
+			Validate: func(out string) {
+				list := make(map[string]interface{})
+				err := yaml.Unmarshal([]byte(out), &list)
+				if err != nil {
+					t.Fatalf("Output should be valid yaml: %v", err)
+				}
+
+				if list["kind"] != "List" || !strings.Contains(out, "creationTimestamp: null") || !strings.Contains(out, "status:") {
+					t.Fatalf("Output should keep empty fields:\n%s", out)
+				}
+			},
+			ExpectError: false,
+		},
+		{
+			Name:        "Should fail on unknown formats",
+			Format:      RenderFormat("xml"),
+			Opts:        RenderDefaultOpts,
+			Validate:    func(out string) {},
+			ExpectError: true,
+		},
+	}
+
+	for _, tc := range cases {
+		out := &bytes.Buffer{}
+		err := tmpl.RenderWithOptions(out, tc.Format, tc.Opts)
+
+		if tc.ExpectError && err == nil {
+			t.Fatalf("\"%s\" expected an error but got none", tc.Name)
+		}
+
+		if !tc.ExpectError && err != nil {
+			t.Fatalf("\"%s\" did not expect error but got %s", tc.Name, err)
+		}
+
+		tc.Validate(out.String())
+	}
+}
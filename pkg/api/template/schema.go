@@ -0,0 +1,77 @@
+package template
+
+import (
+	"fmt"
+)
+
+// Schema is the subset of a JSON Schema / OpenAPI v3 schema describing
+// template parameters. It marshals to the same json as the apiextensions
+// JSONSchemaProps, so it can be embedded in a CRD validation schema as long
+// as it has no Default, which apiextensions v1beta1 forbids.
+type Schema struct {
+	Type        string             `json:"type"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Default     *string            `json:"default,omitempty"`
+	Pattern     string             `json:"pattern,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+}
+
+// SchemaOpt controls the generated schema. Defaults sets the parameter
+// values as defaults, for consumers other than CRD validation such as form
+// generators.
+type SchemaOpt struct {
+	Defaults bool
+}
+
+var SchemaDefaultOpts = SchemaOpt{
+	Defaults: false,
+}
+
+// ParametersSchema describes the template parameters as an object with a
+// string property per parameter, using SchemaDefaultOpts so it can be used
+// as a CRD validation schema.
+func (t *Tmpl) ParametersSchema() (*Schema, error) {
+	return t.ParametersSchemaWithOptions(SchemaDefaultOpts)
+}
+
+// ParametersSchemaWithOptions describes the template parameters. Parameters
+// that are required and have no default or generated value are listed as
+// required, and parameters generated from an expression get a pattern
+// matching its values.
+func (t *Tmpl) ParametersSchemaWithOptions(opts SchemaOpt) (*Schema, error) {
+	schema := &Schema{
+		Type:       "object",
+		Properties: make(map[string]*Schema, len(t.Source.Parameters)),
+	}
+
+	for _, param := range t.Source.Parameters {
+		property := &Schema{
+			Type:        "string",
+			Title:       param.DisplayName,
+			Description: param.Description,
+		}
+
+		if opts.Defaults && param.Value != "" {
+			value := param.Value
+			property.Default = &value
+		}
+
+		if param.Generate == ExpressionGeneratorName {
+			pattern, err := ExpressionPattern(param.From)
+			if err != nil {
+				return nil, fmt.Errorf("invalid generator expression %s of parameter %s: %v", param.From, param.Name, err)
+			}
+			property.Pattern = pattern
+		}
+
+		if param.Required && param.Value == "" && param.Generate == "" {
+			schema.Required = append(schema.Required, param.Name)
+		}
+
+		schema.Properties[param.Name] = property
+	}
+
+	return schema, nil
+}
@@ -0,0 +1,119 @@
+package template
+
+import (
+	"encoding/json"
+	"github.com/openshift/api/template/v1"
+	"regexp"
+	"testing"
+)
+
+func TestTmpl_ParametersSchema(t *testing.T) {
+	cases := []struct {
+		Name        string
+		Parameters  []v1.Parameter
+		Opts        SchemaOpt
+		Validate    func(schema *Schema)
+		ExpectError bool
+	}{
+		{
+			Name: "Should describe every parameter",
+			Parameters: []v1.Parameter{
+				{Name: "REQUIRED", DisplayName: "Required", Description: "A required value", Required: true},
+				{Name: "DEFAULTED", Value: "value", Required: true},
+				{Name: "OPTIONAL"},
+			},
+			Opts: SchemaOpt{Defaults: true},
+			Validate: func(schema *Schema) {
+				if schema.Type != "object" || len(schema.Properties) != 3 {
+					t.Fatalf("Schema should have a property per parameter: %v", schema)
+				}
+
+				if len(schema.Required) != 1 || schema.Required[0] != "REQUIRED" {
+					t.Fatalf("Only parameters without a value should be required: %v", schema.Required)
+				}
+
+				required := schema.Properties["REQUIRED"]
+				if required.Type != "string" || required.Title != "Required" || required.Description != "A required value" || required.Default != nil {
+					t.Fatalf("Unexpected property: %v", required)
+				}
+
+				if schema.Properties["DEFAULTED"].Default == nil || *schema.Properties["DEFAULTED"].Default != "value" {
+					t.Fatalf("Parameter value should be the default: %v", schema.Properties["DEFAULTED"])
+				}
+			},
+			ExpectError: false,
+		},
+		{
+			Name: "Should not set defaults by default",
+			Parameters: []v1.Parameter{
+				{Name: "DEFAULTED", Value: "value", Required: true},
+			},
+			Opts: SchemaDefaultOpts,
+			Validate: func(schema *Schema) {
+				if schema.Properties["DEFAULTED"].Default != nil || len(schema.Required) != 0 {
+					t.Fatalf("Unexpected schema: %v", schema)
+				}
+			},
+			ExpectError: false,
+		},
+		{
+			Name: "Should derive a pattern from generator expressions",
+			Parameters: []v1.Parameter{
+				{Name: "GENERATED", Generate: "expression", From: "[a-z0-9]{8}", Required: true},
+			},
+			Validate: func(schema *Schema) {
+				if len(schema.Required) != 0 {
+					t.Fatalf("Generated parameters should not be required: %v", schema.Required)
+				}
+
+				pattern := regexp.MustCompile(schema.Properties["GENERATED"].Pattern)
+				if !pattern.MatchString("abcd1234") || pattern.MatchString("ABCD1234") {
+					t.Fatalf("Unexpected pattern: %s", pattern)
+				}
+			},
+			ExpectError: false,
+		},
+		{
+			Name: "Should fail on invalid generator expressions",
+			Parameters: []v1.Parameter{
+				{Name: "GENERATED", Generate: "expression", From: "[z-a]{8}"},
+			},
+			Validate:    func(schema *Schema) {},
+			ExpectError: true,
+		},
+	}
+
+	for _, tc := range cases {
+		tmpl := &Tmpl{Source: &v1.Template{Parameters: tc.Parameters}}
+		schema, err := tmpl.ParametersSchemaWithOptions(tc.Opts)
+
+		if tc.ExpectError && err == nil {
+			t.Fatalf("\"%s\" expected an error but got none", tc.Name)
+		}
+
+		if !tc.ExpectError && err != nil {
+			t.Fatalf("\"%s\" did not expect error but got %s", tc.Name, err)
+		}
+
+		tc.Validate(schema)
+	}
+}
+
+func TestSchema_MarshalJSON(t *testing.T) {
+	tmpl := &Tmpl{Source: &v1.Template{Parameters: []v1.Parameter{{Name: "NAME", Value: "app", Description: "Name"}}}}
+
+	schema, err := tmpl.ParametersSchema()
+	if err != nil {
+		t.Fatalf("Test failed: %v", err)
+	}
+
+	b, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatalf("Test failed: %v", err)
+	}
+
+	expected := `{"type":"object","properties":{"NAME":{"type":"string","description":"Name"}}}`
+	if string(b) != expected {
+		t.Fatalf("Unexpected schema: %s", b)
+	}
+}
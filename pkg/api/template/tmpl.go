@@ -1,19 +1,37 @@
 package template
 
 import (
+	"context"
+	"fmt"
 	"github.com/integr8ly/operator-sdk-openshift-utils/pkg/api/kubernetes"
 	"github.com/integr8ly/operator-sdk-openshift-utils/pkg/api/schemes"
 	v1template "github.com/openshift/api/template/v1"
 	"io"
 	"io/ioutil"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/rest"
+	"time"
 )
 
 func New(restConfig *rest.Config, data []byte) (*Tmpl, error) {
+	return NewWithOptions(restConfig, data, TmplDefaultOpts)
+}
+
+// NewWithOptions creates a template from its json or yaml source.
+func NewWithOptions(restConfig *rest.Config, data []byte, opts TmplOpt) (*Tmpl, error) {
+	data, err := kubernetes.AsJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
 	tmpl := &Tmpl{
-		Raw: data,
+		Raw:  data,
+		Opts: opts,
 	}
 
 	res, err := kubernetes.LoadKubernetesResource(tmpl.Raw)
@@ -22,7 +40,11 @@ func New(restConfig *rest.Config, data []byte) (*Tmpl, error) {
 	}
 	tmpl.Source = res.(*v1template.Template)
 
-	err = tmpl.Bootstrap(restConfig, TmplDefaultOpts)
+	if restConfig == nil {
+		return tmpl, nil
+	}
+
+	err = tmpl.Bootstrap(restConfig, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -43,7 +65,11 @@ func NoFilterFn(obj *runtime.Object) error {
 	return nil
 }
 
+// Bootstrap creates the rest client of the template. The fields opts does
+// not set fall back to TmplDefaultOpts, as for the processing request.
 func (t *Tmpl) Bootstrap(restConfig *rest.Config, opts TmplOpt) error {
+	opts = withDefaults(opts)
+
 	config := rest.CopyConfig(restConfig)
 	config.GroupVersion = &schema.GroupVersion{
 		Group:   opts.ApiGroup,
@@ -64,88 +90,287 @@ func (t *Tmpl) Bootstrap(restConfig *rest.Config, opts TmplOpt) error {
 	}
 
 	t.RestClient = restClient
+	t.Opts = opts
 
 	return nil
 }
 
 func (t *Tmpl) Process(params map[string]string, ns string) error {
+	return t.ProcessWithContext(context.TODO(), params, ns, ProcessOpt{})
+}
+
+// ProcessWithContext processes the template through the api server, honoring
+// the context deadline and cancellation. Transient failures are retried using
+// opts.Backoff and every returned error is a *ProcessError.
+func (t *Tmpl) ProcessWithContext(ctx context.Context, params map[string]string, ns string, opts ProcessOpt) error {
 	var err error
 
-	t.fillParams(params)
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
 
-	uo, err := kubernetes.UnstructuredFromRuntimeObject(t.Source.DeepCopyObject())
+	source, err := t.fillParams(params)
 	if err != nil {
-		return err
+		return newProcessError(ProcessErrorValidation, err)
 	}
 
-	jsonData, err := uo.MarshalJSON()
+	uo, err := kubernetes.UnstructuredFromRuntimeObject(source)
 	if err != nil {
-		return err
+		return newProcessError(ProcessErrorValidation, err)
 	}
 
-	result := t.RestClient.
-		Post().
-		Namespace(ns).
-		Body(jsonData).
-		Resource("processedtemplates").
-		Do()
+	apiOpts := t.apiOpts()
+	uo.SetAPIVersion(schema.GroupVersion{Group: apiOpts.ApiGroup, Version: apiOpts.ApiVersion}.String())
+	uo.SetKind(apiOpts.ApiKind)
 
-	if result.Error() != nil {
-		return result.Error()
+	jsonData, err := uo.MarshalJSON()
+	if err != nil {
+		return newProcessError(ProcessErrorValidation, err)
 	}
 
-	data, err := result.Raw()
+	data, err := t.post(ctx, ns, apiOpts.ApiResource, jsonData, opts.Backoff)
 	if err != nil {
 		return err
 	}
 
-	templateObject, err := kubernetes.LoadKubernetesResource(data)
+	processed, err := decodeProcessed(data, apiOpts)
 	if err != nil {
-		return err
+		return newProcessError(ProcessErrorTransport, err)
 	}
 
-	t.Source = templateObject.(*v1template.Template)
-
-	err = t.fillObjects(t.Source.Objects)
+	err = t.fillObjects(processed.Objects)
 	if err != nil {
-		return err
+		return newProcessError(ProcessErrorValidation, err)
 	}
 
+	t.Processed = processed
+
 	return nil
 }
 
+// apiOpts returns the template options, falling back to the defaults for
+// the fields that were not set.
+func (t *Tmpl) apiOpts() TmplOpt {
+	return withDefaults(t.Opts)
+}
+
+func withDefaults(opts TmplOpt) TmplOpt {
+	if opts.ApiKind == "" {
+		opts.ApiKind = TmplDefaultOpts.ApiKind
+	}
+	if opts.ApiVersion == "" {
+		opts.ApiVersion = TmplDefaultOpts.ApiVersion
+	}
+	if opts.ApiPath == "" {
+		opts.ApiPath = TmplDefaultOpts.ApiPath
+		opts.ApiGroup = TmplDefaultOpts.ApiGroup
+	}
+	if opts.ApiMimetype == "" {
+		opts.ApiMimetype = TmplDefaultOpts.ApiMimetype
+	}
+	if opts.ApiResource == "" {
+		opts.ApiResource = TmplDefaultOpts.ApiResource
+	}
+
+	return opts
+}
+
+// decodeProcessed decodes the template returned by the api server. The
+// response has the kind of the request, e.g. the group-less v1 Template of
+// the legacy /oapi path, which is mapped to the registered template kind.
+func decodeProcessed(data []byte, opts TmplOpt) (*v1template.Template, error) {
+	u := &unstructured.Unstructured{}
+	err := u.UnmarshalJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	requested := schema.GroupVersion{Group: opts.ApiGroup, Version: opts.ApiVersion}.WithKind(opts.ApiKind)
+	if u.GroupVersionKind() == requested {
+		u.SetGroupVersionKind(v1template.GroupVersion.WithKind("Template"))
+	}
+
+	obj, err := kubernetes.RuntimeObjectFromUnstructured(u)
+	if err != nil {
+		return nil, err
+	}
+
+	processed, ok := obj.(*v1template.Template)
+	if !ok {
+		return nil, fmt.Errorf("unexpected processed object of kind %s", u.GroupVersionKind())
+	}
+
+	return processed, nil
+}
+
+func (t *Tmpl) post(ctx context.Context, ns string, resource string, jsonData []byte, backoff wait.Backoff) ([]byte, error) {
+	duration := backoff.Duration
+
+	for attempt := 1; ; attempt++ {
+		data, err := t.RestClient.
+			Post().
+			Context(ctx).
+			Namespace(ns).
+			Body(jsonData).
+			Resource(resource).
+			Do().
+			Raw()
+
+		if err == nil {
+			return data, nil
+		}
+
+		if ctx.Err() != nil {
+			return nil, newProcessError(ProcessErrorTransport, ctx.Err())
+		}
+
+		if !isTransient(err) || attempt >= backoff.Steps {
+			return nil, classifyError(err)
+		}
+
+		delay := duration
+		if backoff.Jitter > 0 {
+			delay = wait.Jitter(duration, backoff.Jitter)
+		}
+		if backoff.Factor > 0 {
+			duration = time.Duration(float64(duration) * backoff.Factor)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, newProcessError(ProcessErrorTransport, ctx.Err())
+		case <-time.After(delay):
+		}
+	}
+}
+
 func (t *Tmpl) fillObjects(rawObjects []runtime.RawExtension) error {
+	objects := make([]runtime.Object, 0, len(rawObjects))
+	opts := kubernetes.DecodeOpt{AllowUnstructured: t.AllowUnstructured}
+
+	load := kubernetes.LoadKubernetesResourceWithOptions
+	if t.Loader != nil {
+		load = t.Loader.LoadKubernetesResource
+	}
+
 	for _, rawObject := range rawObjects {
-		obj, err := kubernetes.LoadKubernetesResource(rawObject.Raw)
+		obj, err := load(rawObject.Raw, opts)
 		if err != nil {
 			return err
 		}
 
-		t.Objects = append(t.Objects, obj)
+		objects = append(objects, obj)
 	}
 
+	t.Objects = objects
+
 	return nil
 }
 
-func (t *Tmpl) fillParams(params map[string]string) {
-	for i, param := range t.Source.Parameters {
+// fillParams returns a copy of the source template with the given values and
+// the generated ones set, leaving the source untouched.
+func (t *Tmpl) fillParams(params map[string]string) (*v1template.Template, error) {
+	generators := t.Generators
+	if generators == nil {
+		generators = DefaultGenerators()
+	}
+
+	source := t.Source.DeepCopy()
+
+	for i, param := range source.Parameters {
 		if value, ok := params[param.Name]; ok {
-			t.Source.Parameters[i].Value = value
+			source.Parameters[i].Value = value
+		}
+
+		if len(source.Parameters[i].Value) > 0 || len(param.Generate) == 0 {
+			continue
 		}
+
+		generator, ok := generators[param.Generate]
+		if !ok {
+			return nil, fmt.Errorf("unable to find the '%s' generator for parameter %s", param.Generate, param.Name)
+		}
+
+		value, err := generator.GenerateValue(param.From)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate value for parameter %s: %v", param.Name, err)
+		}
+
+		source.Parameters[i].Value = value
 	}
+
+	return source, nil
 }
 
+// GetObjects returns a deep copy of the objects kept by filter, transformed
+// by t.Mutators and sorted by t.Order. No objects are returned when a mutator
+// fails on one of them, use GetObjectsWithError to know about it.
 func (t *Tmpl) GetObjects(filter FilterFn) []runtime.Object {
+	objects, errs := t.objects(filter)
+	if len(errs) > 0 {
+		return make([]runtime.Object, 0)
+	}
+
+	return objects
+}
+
+// GetObjectsWithError is GetObjects failing when a mutator fails on one of
+// the objects. The returned error aggregates every failed mutation.
+func (t *Tmpl) GetObjectsWithError(filter FilterFn) ([]runtime.Object, error) {
+	objects, errs := t.objects(filter)
+	if len(errs) > 0 {
+		return nil, utilerrors.NewAggregate(errs)
+	}
+
+	return objects, nil
+}
+
+func (t *Tmpl) objects(filter FilterFn) ([]runtime.Object, []error) {
 	objects := make([]runtime.Object, 0)
+	errs := make([]error, 0)
 
 	for _, obj := range t.Objects {
 		err := filter(&obj)
-		if err == nil {
-			objects = append(objects, obj.DeepCopyObject())
+		if err != nil {
+			continue
+		}
+
+		obj = obj.DeepCopyObject()
+		err = t.mutate(obj)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to mutate %s: %v", objectName(obj), err))
+			continue
 		}
+		objects = append(objects, obj)
 	}
 
-	return objects
+	if t.Order != nil {
+		t.Order(objects)
+	}
+
+	return objects, errs
+}
+
+func objectName(obj runtime.Object) string {
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	if accessor, err := meta.Accessor(obj); err == nil {
+		return kind + " " + accessor.GetName()
+	}
+
+	return kind
+}
+
+func (t *Tmpl) mutate(obj runtime.Object) error {
+	for _, mutator := range t.Mutators {
+		err := mutator(obj)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func (t *Tmpl) CopyObjects(filter FilterFn, objects *[]runtime.Object) {
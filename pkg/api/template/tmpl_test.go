@@ -2,18 +2,27 @@ package template
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"github.com/integr8ly/operator-sdk-openshift-utils/pkg/api/kubernetes"
 	"github.com/openshift/api/template/v1"
 	"io"
 	"io/ioutil"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
 	kubescheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/rest/fake"
+	"math/rand"
 	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
 	"testing"
+	"time"
 )
 
 func objBody(object interface{}) io.ReadCloser {
@@ -58,6 +67,18 @@ func TestNew(t *testing.T) {
 			},
 			ExpectError: false,
 		},
+		{
+			Name: "Should create a new template from yaml",
+			Template: func() (*Tmpl, error) {
+				b, err := ioutil.ReadFile("_testdata/template.yaml")
+				if err != nil {
+					return nil, err
+				}
+
+				return New(nil, b)
+			},
+			ExpectError: false,
+		},
 	}
 
 	for _, tc := range cases {
@@ -271,6 +292,49 @@ func TestTmpl_FillObjects(t *testing.T) {
 			Validate:    func(tmpl *Tmpl) {},
 			ExpectError: true,
 		},
+		{
+			Name:     "Should decode objects with the loader of the template",
+			Template: &Tmpl{Loader: kubernetes.NewLoader(runtime.NewScheme())},
+			Extensions: func() []runtime.RawExtension {
+				b, err := ioutil.ReadFile("_testdata/pod.json")
+				if err != nil {
+					t.Fatalf("Failed to open mock file: %v", err)
+				}
+
+				return []runtime.RawExtension{{Raw: b}}
+			},
+			Validate:    func(tmpl *Tmpl) {},
+			ExpectError: true,
+		},
+		{
+			Name:     "Should keep unknown kinds unstructured",
+			Template: &Tmpl{AllowUnstructured: true},
+			Extensions: func() []runtime.RawExtension {
+				exts := make([]runtime.RawExtension, 0)
+				for _, path := range []string{"pod.json", "custom-object.json"} {
+					b, err := ioutil.ReadFile("_testdata/" + path)
+					if err != nil {
+						t.Fatalf("Failed to open mock file: %v", err)
+					}
+					ext := runtime.RawExtension{
+						Raw: b,
+					}
+					exts = append(exts, ext)
+				}
+
+				return exts
+			},
+			Validate: func(tmpl *Tmpl) {
+				if len(tmpl.Objects) != 2 {
+					t.Fatalf("Failed to fill template objects: %v", tmpl.Objects)
+				}
+
+				if kubernetes.IsUnstructured(tmpl.Objects[0]) || !kubernetes.IsUnstructured(tmpl.Objects[1]) {
+					t.Fatalf("Only the custom object should be unstructured: %v", tmpl.Objects)
+				}
+			},
+			ExpectError: false,
+		},
 	}
 
 	for _, tc := range cases {
@@ -432,7 +496,7 @@ func TestTmpl_FillParams(t *testing.T) {
 		Name     string
 		Params   map[string]string
 		Template *Tmpl
-		Validate func(tmpl *Tmpl, params map[string]string)
+		Validate func(tmpl *v1.Template, params map[string]string)
 	}{
 		{
 			Name: "Should fill template params",
@@ -452,8 +516,8 @@ func TestTmpl_FillParams(t *testing.T) {
 					},
 				},
 			},
-			Validate: func(tmpl *Tmpl, params map[string]string) {
-				for _, param := range tmpl.Source.Parameters {
+			Validate: func(tmpl *v1.Template, params map[string]string) {
+				for _, param := range tmpl.Parameters {
 					if value, ok := params[param.Name]; ok {
 						if value != param.Value {
 							t.Fatalf("Value differs [%s] = %s", value, param.Value)
@@ -462,6 +526,39 @@ func TestTmpl_FillParams(t *testing.T) {
 				}
 			},
 		},
+		{
+			Name:   "Should generate missing template params",
+			Params: map[string]string{},
+			Template: &Tmpl{
+				Source: &v1.Template{
+					Parameters: []v1.Parameter{
+						{
+							Name:     "p1",
+							Generate: "expression",
+							From:     "[a-z0-9]{8}",
+						},
+						{
+							Name:     "p2",
+							Value:    "fixed",
+							Generate: "expression",
+							From:     "[a-z0-9]{8}",
+						},
+					},
+				},
+				Generators: map[string]Generator{
+					ExpressionGeneratorName: NewExpressionGenerator(rand.New(rand.NewSource(1))),
+				},
+			},
+			Validate: func(tmpl *v1.Template, params map[string]string) {
+				if !regexp.MustCompile("^[a-z0-9]{8}$").MatchString(tmpl.Parameters[0].Value) {
+					t.Fatalf("Failed to generate value: %s", tmpl.Parameters[0].Value)
+				}
+
+				if tmpl.Parameters[1].Value != "fixed" {
+					t.Fatalf("Generator should not override value: %s", tmpl.Parameters[1].Value)
+				}
+			},
+		},
 		{
 			Name:   "Template should have 0 parameters",
 			Params: map[string]string{},
@@ -470,8 +567,8 @@ func TestTmpl_FillParams(t *testing.T) {
 					Parameters: []v1.Parameter{},
 				},
 			},
-			Validate: func(tmpl *Tmpl, params map[string]string) {
-				if len(tmpl.Source.Parameters) > 0 {
+			Validate: func(tmpl *v1.Template, params map[string]string) {
+				if len(tmpl.Parameters) > 0 {
 					t.Fatal("Template parameters property should be empty")
 				}
 			},
@@ -479,7 +576,270 @@ func TestTmpl_FillParams(t *testing.T) {
 	}
 
 	for _, tc := range cases {
-		tc.Template.fillParams(tc.Params)
-		tc.Validate(tc.Template, tc.Params)
+		source := tc.Template.Source.DeepCopy()
+
+		filled, err := tc.Template.fillParams(tc.Params)
+		if err != nil {
+			t.Fatalf("Test failed: %v", err)
+		}
+
+		if !reflect.DeepEqual(source, tc.Template.Source) {
+			t.Fatalf("Template source should not be modified: %v", tc.Template.Source)
+		}
+
+		tc.Validate(filled, tc.Params)
+	}
+}
+
+func TestTmpl_ProcessWithContext(t *testing.T) {
+	b, err := ioutil.ReadFile("_testdata/template.json")
+	if err != nil {
+		t.Fatalf("Failed to open mock file: %v", err)
+	}
+
+	backoff := wait.Backoff{
+		Duration: time.Millisecond,
+		Factor:   2,
+		Steps:    3,
+	}
+
+	cases := []struct {
+		Name      string
+		Codes     []int
+		Context   func() context.Context
+		Opts      ProcessOpt
+		Requests  int
+		ErrorType ProcessErrorType
+	}{
+		{
+			Name:      "Should process template",
+			Codes:     []int{201},
+			Context:   context.Background,
+			Opts:      ProcessOpt{Backoff: backoff},
+			Requests:  1,
+			ErrorType: "",
+		},
+		{
+			Name:      "Should retry transient errors",
+			Codes:     []int{503, 429, 201},
+			Context:   context.Background,
+			Opts:      ProcessOpt{Backoff: backoff},
+			Requests:  3,
+			ErrorType: "",
+		},
+		{
+			Name:      "Should give up after backoff steps",
+			Codes:     []int{500, 500, 500, 201},
+			Context:   context.Background,
+			Opts:      ProcessOpt{Backoff: backoff},
+			Requests:  3,
+			ErrorType: ProcessErrorTransport,
+		},
+		{
+			Name:      "Should not retry auth errors",
+			Codes:     []int{401, 201},
+			Context:   context.Background,
+			Opts:      ProcessOpt{Backoff: backoff},
+			Requests:  1,
+			ErrorType: ProcessErrorAuth,
+		},
+		{
+			Name:      "Should not retry validation errors",
+			Codes:     []int{422, 201},
+			Context:   context.Background,
+			Opts:      ProcessOpt{Backoff: backoff},
+			Requests:  1,
+			ErrorType: ProcessErrorValidation,
+		},
+		{
+			Name:  "Should stop retrying when the context is done",
+			Codes: []int{503, 503, 503},
+			Context: func() context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx
+			},
+			Opts: ProcessOpt{
+				Backoff: wait.Backoff{
+					Duration: time.Hour,
+					Steps:    3,
+				},
+			},
+			Requests:  1,
+			ErrorType: ProcessErrorTransport,
+		},
+	}
+
+	for _, tc := range cases {
+		requests := 0
+		client := &fake.RESTClient{
+			NegotiatedSerializer: kubescheme.Codecs,
+			Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+				code := tc.Codes[requests]
+				requests++
+
+				header := http.Header{}
+				header.Set("Content-Type", "application/json")
+				body := ioutil.NopCloser(bytes.NewReader(b))
+				if code >= 400 {
+					body = ioutil.NopCloser(bytes.NewReader([]byte{}))
+				}
+
+				return &http.Response{StatusCode: code, Header: header, Body: body}, nil
+			}),
+		}
+
+		tmpl := &Tmpl{
+			Raw:        b,
+			Source:     &v1.Template{},
+			RestClient: client,
+		}
+
+		err := tmpl.ProcessWithContext(tc.Context(), map[string]string{}, "test", tc.Opts)
+
+		if tc.ErrorType == "" && err != nil {
+			t.Fatalf("\"%s\" did not expect error but got %s", tc.Name, err)
+		}
+
+		if tc.ErrorType != "" && !isProcessErrorType(err, tc.ErrorType) {
+			t.Fatalf("\"%s\" expected %s error but got %v", tc.Name, tc.ErrorType, err)
+		}
+
+		if requests != tc.Requests {
+			t.Fatalf("\"%s\" expected %d requests but got %d", tc.Name, tc.Requests, requests)
+		}
+	}
+}
+
+func TestProcessError_Status(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Err      error
+		Validate func(err error)
+	}{
+		{
+			Name: "Should keep the api status of the cause",
+			Err:  apierrors.NewNotFound(v1.Resource("processedtemplates"), "test"),
+			Validate: func(err error) {
+				if !apierrors.IsNotFound(err) {
+					t.Fatalf("Expected a not found error but got %v", err)
+				}
+			},
+		},
+		{
+			Name: "Should not report a reason for other causes",
+			Err:  errors.New("connection refused"),
+			Validate: func(err error) {
+				if apierrors.ReasonForError(err) != metav1.StatusReasonUnknown {
+					t.Fatalf("Unexpected reason: %s", apierrors.ReasonForError(err))
+				}
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		err := classifyError(tc.Err)
+
+		if err.Unwrap() != tc.Err {
+			t.Fatalf("\"%s\" expected the cause to be unwrapped but got %v", tc.Name, err.Unwrap())
+		}
+
+		tc.Validate(err)
+	}
+}
+
+func TestNewWithOptions(t *testing.T) {
+	b, err := ioutil.ReadFile("_testdata/template.json")
+	if err != nil {
+		t.Fatalf("Failed to open mock file: %v", err)
+	}
+
+	cases := []struct {
+		Name       string
+		Opts       TmplOpt
+		Path       string
+		ApiVersion string
+		Kind       string
+		Response   []byte
+	}{
+		{
+			Name:       "Should use default options",
+			Opts:       TmplDefaultOpts,
+			Path:       "/apis/template.openshift.io/v1/namespaces/test/processedtemplates",
+			ApiVersion: "template.openshift.io/v1",
+			Kind:       "Template",
+			Response:   b,
+		},
+		{
+			Name:       "Should use legacy options",
+			Opts:       TmplLegacyOpts,
+			Path:       "/oapi/v1/namespaces/test/processedtemplates",
+			ApiVersion: "v1",
+			Kind:       "Template",
+			Response:   bytes.Replace(b, []byte(`"apiVersion": "template.openshift.io/v1"`), []byte(`"apiVersion": "v1"`), 1),
+		},
+		{
+			Name:       "Should use defaults for the options that are not set",
+			Opts:       TmplOpt{ApiResource: "mytemplates"},
+			Path:       "/apis/template.openshift.io/v1/namespaces/test/mytemplates",
+			ApiVersion: "template.openshift.io/v1",
+			Kind:       "Template",
+			Response:   b,
+		},
+		{
+			Name: "Should use custom resource",
+			Opts: TmplOpt{
+				ApiKind:     "CustomTemplate",
+				ApiVersion:  "v1beta1",
+				ApiMimetype: "application/json",
+				ApiPath:     "/proxy",
+				ApiGroup:    "templates.example.com",
+				ApiResource: "rendered",
+			},
+			Path:       "/proxy/templates.example.com/v1beta1/namespaces/test/rendered",
+			ApiVersion: "templates.example.com/v1beta1",
+			Kind:       "CustomTemplate",
+			Response: bytes.Replace(
+				bytes.Replace(b, []byte(`"apiVersion": "template.openshift.io/v1"`), []byte(`"apiVersion": "templates.example.com/v1beta1"`), 1),
+				[]byte(`"kind": "Template"`), []byte(`"kind": "CustomTemplate"`), 1,
+			),
+		},
+	}
+
+	for _, tc := range cases {
+		var path string
+		var body map[string]interface{}
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			path = req.URL.Path
+			json.NewDecoder(req.Body).Decode(&body)
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(201)
+			w.Write(tc.Response)
+		}))
+
+		tmpl, err := NewWithOptions(&rest.Config{Host: server.URL}, b, tc.Opts)
+		if err != nil {
+			t.Fatalf("\"%s\" failed to create template: %v", tc.Name, err)
+		}
+
+		err = tmpl.Process(map[string]string{}, "test")
+		server.Close()
+		if err != nil {
+			t.Fatalf("\"%s\" failed to process template: %v", tc.Name, err)
+		}
+
+		if len(tmpl.GetObjects(NoFilterFn)) == 0 {
+			t.Fatalf("\"%s\" expected the processed objects to be decoded", tc.Name)
+		}
+
+		if path != tc.Path {
+			t.Fatalf("\"%s\" expected request to %s but got %s", tc.Name, tc.Path, path)
+		}
+
+		if body["apiVersion"] != tc.ApiVersion || body["kind"] != tc.Kind {
+			t.Fatalf("\"%s\" unexpected request body type: %v %v", tc.Name, body["apiVersion"], body["kind"])
+		}
 	}
 }
@@ -1,26 +1,59 @@
 package template
 
 import (
+	"github.com/integr8ly/operator-sdk-openshift-utils/pkg/api/kubernetes"
 	v1template "github.com/openshift/api/template/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/rest"
+	"time"
 )
 
 var (
 	TmplDefaultOpts = TmplOpt{
+		ApiKind:     "Template",
 		ApiVersion:  "v1",
 		ApiMimetype: "application/json",
 		ApiPath:     "/apis",
 		ApiGroup:    "template.openshift.io",
 		ApiResource: "processedtemplates",
 	}
+
+	TmplLegacyOpts = TmplOpt{
+		ApiKind:     "Template",
+		ApiVersion:  "v1",
+		ApiMimetype: "application/json",
+		ApiPath:     "/oapi",
+		ApiResource: "processedtemplates",
+	}
+
+	ProcessDefaultOpts = ProcessOpt{
+		Timeout: 30 * time.Second,
+		Backoff: wait.Backoff{
+			Duration: 500 * time.Millisecond,
+			Factor:   2,
+			Jitter:   0.1,
+			Steps:    5,
+		},
+	}
 )
 
 type Tmpl struct {
 	RestClient rest.Interface
 	Source     *v1template.Template
+	Processed  *v1template.Template
+	Opts       TmplOpt
 	Raw        []byte
 	Objects    []runtime.Object
+	Generators map[string]Generator
+	Order      OrderFn
+	Mutators   []MutatorFn
+	// AllowUnstructured keeps objects of kinds unknown to the schemes
+	// package as *unstructured.Unstructured instead of failing to process.
+	AllowUnstructured bool
+	// Loader decodes the processed objects, nil for the types of the
+	// schemes package.
+	Loader *kubernetes.Loader
 }
 
 type FilterFn func(obj *runtime.Object) error
@@ -33,3 +66,11 @@ type TmplOpt struct {
 	ApiMimetype string
 	ApiResource string
 }
+
+type ProcessOpt struct {
+	Timeout time.Duration
+	Backoff wait.Backoff
+}
+
+// MutatorFn transforms obj in place.
+type MutatorFn func(obj runtime.Object) error
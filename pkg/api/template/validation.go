@@ -0,0 +1,146 @@
+package template
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type ValidationErrorType string
+
+const (
+	ValidationErrorRequired   ValidationErrorType = "Required"
+	ValidationErrorUndeclared ValidationErrorType = "Undeclared"
+	ValidationErrorPattern    ValidationErrorType = "Pattern"
+)
+
+type ValidationError struct {
+	Type      ValidationErrorType
+	Parameter string
+	Value     string
+	Source    string
+	Message   string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Source != "" {
+		return fmt.Sprintf("parameter %s from %s: %s", e.Parameter, e.Source, e.Message)
+	}
+
+	return fmt.Sprintf("parameter %s: %s", e.Parameter, e.Message)
+}
+
+// ValidationErrors aggregates every problem found by Validate.
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+
+	return fmt.Sprintf("template validation failed: [%s]", strings.Join(messages, ", "))
+}
+
+// Validate checks the given parameter values against the template
+// parameters without modifying the template. It returns nil or a
+// ValidationErrors value holding one entry per problem.
+func (t *Tmpl) Validate(params map[string]string) error {
+	errs := make(ValidationErrors, 0)
+	declared := make(map[string]bool)
+
+	for _, param := range t.Source.Parameters {
+		declared[param.Name] = true
+
+		value, supplied := params[param.Name]
+		if !supplied {
+			value = param.Value
+		}
+
+		if len(value) == 0 {
+			if param.Required && len(param.Generate) == 0 {
+				errs = append(errs, &ValidationError{
+					Type:      ValidationErrorRequired,
+					Parameter: param.Name,
+					Message:   "required value is missing",
+				})
+			}
+			continue
+		}
+
+		if param.Generate != ExpressionGeneratorName {
+			continue
+		}
+
+		exp, err := compileExpression(param.From)
+		if err != nil {
+			errs = append(errs, &ValidationError{
+				Type:      ValidationErrorPattern,
+				Parameter: param.Name,
+				Value:     value,
+				Message:   fmt.Sprintf("invalid generator expression %s: %v", param.From, err),
+			})
+			continue
+		}
+
+		if !exp.MatchString(value) {
+			errs = append(errs, &ValidationError{
+				Type:      ValidationErrorPattern,
+				Parameter: param.Name,
+				Value:     value,
+				Message:   fmt.Sprintf("value does not match expression %s", param.From),
+			})
+		}
+	}
+
+	undeclared := make([]string, 0)
+	for name := range params {
+		if !declared[name] {
+			undeclared = append(undeclared, name)
+		}
+	}
+	sort.Strings(undeclared)
+
+	for _, name := range undeclared {
+		errs = append(errs, &ValidationError{
+			Type:      ValidationErrorUndeclared,
+			Parameter: name,
+			Value:     params[name],
+			Message:   "parameter is not declared in the template",
+		})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func compileExpression(from string) (*regexp.Regexp, error) {
+	pattern, err := ExpressionPattern(from)
+	if err != nil {
+		return nil, err
+	}
+
+	return regexp.Compile(pattern)
+}
+
+// ValidateParams is Validate for values read from parameter sources. The
+// returned errors name the source of the offending values.
+func (t *Tmpl) ValidateParams(params Params) error {
+	err := t.Validate(params.Values())
+	errs, ok := err.(ValidationErrors)
+	if !ok {
+		return err
+	}
+
+	for _, e := range errs {
+		if param, ok := params[e.Parameter]; ok {
+			e.Source = param.Source
+		}
+	}
+
+	return errs
+}
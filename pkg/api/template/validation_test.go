@@ -0,0 +1,119 @@
+package template
+
+import (
+	"github.com/openshift/api/template/v1"
+	"testing"
+)
+
+func TestTmpl_Validate(t *testing.T) {
+	source := &v1.Template{
+		Parameters: []v1.Parameter{
+			{
+				Name:     "REQUIRED",
+				Required: true,
+			},
+			{
+				Name:     "DEFAULTED",
+				Value:    "value",
+				Required: true,
+			},
+			{
+				Name:     "GENERATED",
+				Generate: "expression",
+				From:     "[a-z0-9]{8}",
+				Required: true,
+			},
+			{
+				Name:     "OVERSIZED",
+				Generate: "expression",
+				From:     "[a-z]{2000}",
+			},
+			{
+				Name: "OPTIONAL",
+			},
+		},
+	}
+
+	cases := []struct {
+		Name     string
+		Params   map[string]string
+		Expected []ValidationErrorType
+	}{
+		{
+			Name: "Should validate parameters",
+			Params: map[string]string{
+				"REQUIRED": "value",
+			},
+			Expected: []ValidationErrorType{},
+		},
+		{
+			Name:   "Should report missing required parameter",
+			Params: map[string]string{},
+			Expected: []ValidationErrorType{
+				ValidationErrorRequired,
+			},
+		},
+		{
+			Name: "Should report every problem",
+			Params: map[string]string{
+				"DEFAULTED": "",
+				"GENERATED": "NOT-VALID",
+				"UNKNOWN":   "value",
+			},
+			Expected: []ValidationErrorType{
+				ValidationErrorRequired,
+				ValidationErrorRequired,
+				ValidationErrorPattern,
+				ValidationErrorUndeclared,
+			},
+		},
+		{
+			Name: "Should report an expression over the generated length limit",
+			Params: map[string]string{
+				"REQUIRED":  "value",
+				"OVERSIZED": "abc",
+			},
+			Expected: []ValidationErrorType{
+				ValidationErrorPattern,
+			},
+		},
+		{
+			Name: "Should accept value matching generator expression",
+			Params: map[string]string{
+				"REQUIRED":  "value",
+				"GENERATED": "abcd1234",
+			},
+			Expected: []ValidationErrorType{},
+		},
+	}
+
+	for _, tc := range cases {
+		tmpl := &Tmpl{
+			Source: source.DeepCopy(),
+		}
+
+		err := tmpl.Validate(tc.Params)
+
+		if len(tc.Expected) == 0 {
+			if err != nil {
+				t.Fatalf("\"%s\" did not expect error but got %s", tc.Name, err)
+			}
+			continue
+		}
+
+		errs, ok := err.(ValidationErrors)
+		if !ok {
+			t.Fatalf("\"%s\" expected validation errors but got %v", tc.Name, err)
+		}
+
+		if len(errs) != len(tc.Expected) {
+			t.Fatalf("\"%s\" expected %d errors but got %d: %v", tc.Name, len(tc.Expected), len(errs), errs)
+		}
+
+		for i, e := range errs {
+			if e.Type != tc.Expected[i] {
+				t.Fatalf("\"%s\" expected error %s but got %s", tc.Name, tc.Expected[i], e.Type)
+			}
+		}
+	}
+}
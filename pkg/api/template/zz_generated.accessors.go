@@ -0,0 +1,278 @@
+// Code generated by accessors_generate.go. DO NOT EDIT.
+
+package template
+
+import (
+	appsv1 "github.com/openshift/api/apps/v1"
+	authorizationv1 "github.com/openshift/api/authorization/v1"
+	buildv1 "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// ConfigMaps returns a deep copy of the v1 ConfigMap objects.
+func (t *Tmpl) ConfigMaps() []*corev1.ConfigMap {
+	objects := make([]*corev1.ConfigMap, 0)
+	t.ObjectsOfType(&objects)
+
+	return objects
+}
+
+// Endpoints returns a deep copy of the v1 Endpoints objects.
+func (t *Tmpl) Endpoints() []*corev1.Endpoints {
+	objects := make([]*corev1.Endpoints, 0)
+	t.ObjectsOfType(&objects)
+
+	return objects
+}
+
+// LimitRanges returns a deep copy of the v1 LimitRange objects.
+func (t *Tmpl) LimitRanges() []*corev1.LimitRange {
+	objects := make([]*corev1.LimitRange, 0)
+	t.ObjectsOfType(&objects)
+
+	return objects
+}
+
+// Namespaces returns a deep copy of the v1 Namespace objects.
+func (t *Tmpl) Namespaces() []*corev1.Namespace {
+	objects := make([]*corev1.Namespace, 0)
+	t.ObjectsOfType(&objects)
+
+	return objects
+}
+
+// PersistentVolumes returns a deep copy of the v1 PersistentVolume objects.
+func (t *Tmpl) PersistentVolumes() []*corev1.PersistentVolume {
+	objects := make([]*corev1.PersistentVolume, 0)
+	t.ObjectsOfType(&objects)
+
+	return objects
+}
+
+// PersistentVolumeClaims returns a deep copy of the v1 PersistentVolumeClaim objects.
+func (t *Tmpl) PersistentVolumeClaims() []*corev1.PersistentVolumeClaim {
+	objects := make([]*corev1.PersistentVolumeClaim, 0)
+	t.ObjectsOfType(&objects)
+
+	return objects
+}
+
+// Pods returns a deep copy of the v1 Pod objects.
+func (t *Tmpl) Pods() []*corev1.Pod {
+	objects := make([]*corev1.Pod, 0)
+	t.ObjectsOfType(&objects)
+
+	return objects
+}
+
+// ReplicationControllers returns a deep copy of the v1 ReplicationController objects.
+func (t *Tmpl) ReplicationControllers() []*corev1.ReplicationController {
+	objects := make([]*corev1.ReplicationController, 0)
+	t.ObjectsOfType(&objects)
+
+	return objects
+}
+
+// ResourceQuotas returns a deep copy of the v1 ResourceQuota objects.
+func (t *Tmpl) ResourceQuotas() []*corev1.ResourceQuota {
+	objects := make([]*corev1.ResourceQuota, 0)
+	t.ObjectsOfType(&objects)
+
+	return objects
+}
+
+// Secrets returns a deep copy of the v1 Secret objects.
+func (t *Tmpl) Secrets() []*corev1.Secret {
+	objects := make([]*corev1.Secret, 0)
+	t.ObjectsOfType(&objects)
+
+	return objects
+}
+
+// Services returns a deep copy of the v1 Service objects.
+func (t *Tmpl) Services() []*corev1.Service {
+	objects := make([]*corev1.Service, 0)
+	t.ObjectsOfType(&objects)
+
+	return objects
+}
+
+// ServiceAccounts returns a deep copy of the v1 ServiceAccount objects.
+func (t *Tmpl) ServiceAccounts() []*corev1.ServiceAccount {
+	objects := make([]*corev1.ServiceAccount, 0)
+	t.ObjectsOfType(&objects)
+
+	return objects
+}
+
+// DeploymentConfigs returns a deep copy of the apps.openshift.io/v1 DeploymentConfig objects.
+func (t *Tmpl) DeploymentConfigs() []*appsv1.DeploymentConfig {
+	objects := make([]*appsv1.DeploymentConfig, 0)
+	t.ObjectsOfType(&objects)
+
+	return objects
+}
+
+// OpenShiftClusterRoles returns a deep copy of the authorization.openshift.io/v1 ClusterRole objects.
+func (t *Tmpl) OpenShiftClusterRoles() []*authorizationv1.ClusterRole {
+	objects := make([]*authorizationv1.ClusterRole, 0)
+	t.ObjectsOfType(&objects)
+
+	return objects
+}
+
+// OpenShiftClusterRoleBindings returns a deep copy of the authorization.openshift.io/v1 ClusterRoleBinding objects.
+func (t *Tmpl) OpenShiftClusterRoleBindings() []*authorizationv1.ClusterRoleBinding {
+	objects := make([]*authorizationv1.ClusterRoleBinding, 0)
+	t.ObjectsOfType(&objects)
+
+	return objects
+}
+
+// OpenShiftRoles returns a deep copy of the authorization.openshift.io/v1 Role objects.
+func (t *Tmpl) OpenShiftRoles() []*authorizationv1.Role {
+	objects := make([]*authorizationv1.Role, 0)
+	t.ObjectsOfType(&objects)
+
+	return objects
+}
+
+// OpenShiftRoleBindings returns a deep copy of the authorization.openshift.io/v1 RoleBinding objects.
+func (t *Tmpl) OpenShiftRoleBindings() []*authorizationv1.RoleBinding {
+	objects := make([]*authorizationv1.RoleBinding, 0)
+	t.ObjectsOfType(&objects)
+
+	return objects
+}
+
+// RoleBindingRestrictions returns a deep copy of the authorization.openshift.io/v1 RoleBindingRestriction objects.
+func (t *Tmpl) RoleBindingRestrictions() []*authorizationv1.RoleBindingRestriction {
+	objects := make([]*authorizationv1.RoleBindingRestriction, 0)
+	t.ObjectsOfType(&objects)
+
+	return objects
+}
+
+// Builds returns a deep copy of the build.openshift.io/v1 Build objects.
+func (t *Tmpl) Builds() []*buildv1.Build {
+	objects := make([]*buildv1.Build, 0)
+	t.ObjectsOfType(&objects)
+
+	return objects
+}
+
+// BuildConfigs returns a deep copy of the build.openshift.io/v1 BuildConfig objects.
+func (t *Tmpl) BuildConfigs() []*buildv1.BuildConfig {
+	objects := make([]*buildv1.BuildConfig, 0)
+	t.ObjectsOfType(&objects)
+
+	return objects
+}
+
+// DaemonSets returns a deep copy of the extensions/v1beta1 DaemonSet objects.
+func (t *Tmpl) DaemonSets() []*extensionsv1beta1.DaemonSet {
+	objects := make([]*extensionsv1beta1.DaemonSet, 0)
+	t.ObjectsOfType(&objects)
+
+	return objects
+}
+
+// Deployments returns a deep copy of the extensions/v1beta1 Deployment objects.
+func (t *Tmpl) Deployments() []*extensionsv1beta1.Deployment {
+	objects := make([]*extensionsv1beta1.Deployment, 0)
+	t.ObjectsOfType(&objects)
+
+	return objects
+}
+
+// Ingresses returns a deep copy of the extensions/v1beta1 Ingress objects.
+func (t *Tmpl) Ingresses() []*extensionsv1beta1.Ingress {
+	objects := make([]*extensionsv1beta1.Ingress, 0)
+	t.ObjectsOfType(&objects)
+
+	return objects
+}
+
+// NetworkPolicies returns a deep copy of the extensions/v1beta1 NetworkPolicy objects.
+func (t *Tmpl) NetworkPolicies() []*extensionsv1beta1.NetworkPolicy {
+	objects := make([]*extensionsv1beta1.NetworkPolicy, 0)
+	t.ObjectsOfType(&objects)
+
+	return objects
+}
+
+// PodSecurityPolicies returns a deep copy of the extensions/v1beta1 PodSecurityPolicy objects.
+func (t *Tmpl) PodSecurityPolicies() []*extensionsv1beta1.PodSecurityPolicy {
+	objects := make([]*extensionsv1beta1.PodSecurityPolicy, 0)
+	t.ObjectsOfType(&objects)
+
+	return objects
+}
+
+// ReplicaSets returns a deep copy of the extensions/v1beta1 ReplicaSet objects.
+func (t *Tmpl) ReplicaSets() []*extensionsv1beta1.ReplicaSet {
+	objects := make([]*extensionsv1beta1.ReplicaSet, 0)
+	t.ObjectsOfType(&objects)
+
+	return objects
+}
+
+// ImageStreams returns a deep copy of the image.openshift.io/v1 ImageStream objects.
+func (t *Tmpl) ImageStreams() []*imagev1.ImageStream {
+	objects := make([]*imagev1.ImageStream, 0)
+	t.ObjectsOfType(&objects)
+
+	return objects
+}
+
+// ImageStreamTags returns a deep copy of the image.openshift.io/v1 ImageStreamTag objects.
+func (t *Tmpl) ImageStreamTags() []*imagev1.ImageStreamTag {
+	objects := make([]*imagev1.ImageStreamTag, 0)
+	t.ObjectsOfType(&objects)
+
+	return objects
+}
+
+// ClusterRoles returns a deep copy of the rbac.authorization.k8s.io/v1 ClusterRole objects.
+func (t *Tmpl) ClusterRoles() []*rbacv1.ClusterRole {
+	objects := make([]*rbacv1.ClusterRole, 0)
+	t.ObjectsOfType(&objects)
+
+	return objects
+}
+
+// ClusterRoleBindings returns a deep copy of the rbac.authorization.k8s.io/v1 ClusterRoleBinding objects.
+func (t *Tmpl) ClusterRoleBindings() []*rbacv1.ClusterRoleBinding {
+	objects := make([]*rbacv1.ClusterRoleBinding, 0)
+	t.ObjectsOfType(&objects)
+
+	return objects
+}
+
+// Roles returns a deep copy of the rbac.authorization.k8s.io/v1 Role objects.
+func (t *Tmpl) Roles() []*rbacv1.Role {
+	objects := make([]*rbacv1.Role, 0)
+	t.ObjectsOfType(&objects)
+
+	return objects
+}
+
+// RoleBindings returns a deep copy of the rbac.authorization.k8s.io/v1 RoleBinding objects.
+func (t *Tmpl) RoleBindings() []*rbacv1.RoleBinding {
+	objects := make([]*rbacv1.RoleBinding, 0)
+	t.ObjectsOfType(&objects)
+
+	return objects
+}
+
+// Routes returns a deep copy of the route.openshift.io/v1 Route objects.
+func (t *Tmpl) Routes() []*routev1.Route {
+	objects := make([]*routev1.Route, 0)
+	t.ObjectsOfType(&objects)
+
+	return objects
+}